@@ -1,13 +1,18 @@
 package align
 
+import "time"
+
 // day is used to encapsulate day information
 type day struct {
-	Timestamp        string   // The timestamp of the day
-	AvailablePersons []string // Available people
+	Timestamp        string    // The timestamp of the day, as an ISO date
+	Date             time.Time // The date of the day
+	AvailablePersons []string  // Available people
+	TentativePersons []string  // People who might be available
 }
 
-// align a bunch of schedules together, returning a list of days n people are free
-func align(s map[string]map[string]bool, n int) []day {
+// align a bunch of schedules together, returning a list of days n people are free. Tentative schedules
+// don't count towards n, but are listed on the returned days
+func align(s map[string]map[string]bool, t map[string]map[string]bool, n int) []day {
 	// Make a copy of the schedule map without nil availabilities
 	schedules := make(map[string]map[string]bool)
 	for k, v := range s {
@@ -50,6 +55,14 @@ func align(s map[string]map[string]bool, n int) []day {
 	filter := []day{}
 	for _, day := range days {
 		if len(day.AvailablePersons) >= n {
+			// Add people who might be available
+			day.TentativePersons = []string{}
+			for name, tentative := range t {
+				if tentative[day.Timestamp] {
+					day.TentativePersons = append(day.TentativePersons, name)
+				}
+			}
+
 			filter = append(filter, day)
 		}
 	}
@@ -0,0 +1,215 @@
+package align
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+/* ---- TYPES ---- */
+
+// CalDAVConfig holds all necessary fields for CalDAV calendar functions to run successfully
+type CalDAVConfig struct {
+	Client *http.Client
+}
+
+// caldavMultistatus represents the response of a CalDAV calendar query
+type caldavMultistatus struct {
+	Responses []struct {
+		Href      string `xml:"DAV: href"`
+		Propstats []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+/* ---- GLOBALS ---- */
+
+const caldavQueryBody = `<?xml version="1.0" encoding="utf-8"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop>
+    <C:calendar-data>
+      <C:expand start="%[1]v" end="%[2]v"/>
+    </C:calendar-data>
+  </D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VEVENT">
+        <C:time-range start="%[1]v" end="%[2]v"/>
+      </C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`
+
+/* ---- FUNCTIONS ---- */
+
+// Initialize a CalDAV config. The CalDAV server is read from the align config file
+func InitCalDAV(manager *Manager, client *http.Client) {
+	log.Println("[INFO]: initializing caldav config")
+
+	if manager.config.CalDAV == nil {
+		log.Println("[WARN]: caldav credentials are missing from the config, caldav will not be used")
+		return
+	}
+
+	manager.moduleConfigs["caldav"] = CalDAVConfig{
+		Client: client,
+	}
+}
+
+// Write the chosen event to the shared CalDAV calendar
+func CalDAVPublish(manager *Manager, event Event) error {
+	log.Println("[INFO]: loading caldav config")
+
+	// Attempt to load the caldav config
+	config, ok := manager.moduleConfigs["caldav"].(CalDAVConfig)
+	if !ok {
+		return fmt.Errorf("caldav config has not been initialized")
+	}
+
+	// Check if the client is valid
+	if config.Client == nil {
+		return fmt.Errorf("caldav client is nil")
+	}
+
+	// Check if there is a calendar to write to
+	if manager.config.CalDAV.Calendar == "" {
+		log.Println("[INFO]: no shared caldav calendar, skipping publish")
+		return nil
+	}
+
+	// Each event is stored as its own resource in the calendar
+	location, err := caldavURL(manager, strings.TrimSuffix(manager.config.CalDAV.Calendar, "/")+"/"+url.PathEscape(event.UID)+".ics")
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO]: writing event to caldav calendar '%v'\n", location)
+
+	req, err := http.NewRequest(http.MethodPut, location, bytes.NewReader(event.ICS()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/calendar; charset=utf-8")
+
+	resp, err := caldavDo(config, manager, req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// Read the busy spans from a person's CalDAV calendar. Returns false if the person has no CalDAV calendar
+func caldavBusy(person Person, manager *Manager) ([]busy, bool, error) {
+	if person.CalDAV == "" {
+		return nil, false, nil
+	}
+
+	// Attempt to load the caldav config
+	config, ok := manager.moduleConfigs["caldav"].(CalDAVConfig)
+	if !ok {
+		return nil, true, fmt.Errorf("caldav config has not been initialized")
+	}
+
+	// Check if the client is valid
+	if config.Client == nil {
+		return nil, true, fmt.Errorf("caldav client is nil")
+	}
+
+	location, err := caldavURL(manager, person.CalDAV)
+	if err != nil {
+		return nil, true, err
+	}
+
+	// Only query events in the range of dates persons are asked about
+	dates := manager.generateDates()
+	if len(dates) == 0 {
+		return []busy{}, true, nil
+	}
+	start := dates[0].UTC().Format(icsTimeFormat)
+	end := dates[len(dates)-1].AddDate(0, 0, 1).UTC().Format(icsTimeFormat)
+
+	log.Printf("[INFO]: reading caldav calendar for '%v'\n", person.Name)
+
+	req, err := http.NewRequest("REPORT", location, strings.NewReader(fmt.Sprintf(caldavQueryBody, start, end)))
+	if err != nil {
+		return nil, true, err
+	}
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	req.Header.Set("Depth", "1")
+
+	resp, err := caldavDo(config, manager, req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+
+	// Parse the events in every returned calendar
+	var multistatus caldavMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&multistatus); err != nil {
+		return nil, true, err
+	}
+
+	spans := []busy{}
+	for _, response := range multistatus.Responses {
+		for _, propstat := range response.Propstats {
+			if !strings.Contains(propstat.Status, "200") {
+				continue
+			}
+
+			s, err := parseBusy([]byte(propstat.Prop.CalendarData), manager.loc)
+			if err != nil {
+				return nil, true, err
+			}
+			spans = append(spans, s...)
+		}
+	}
+
+	return spans, true, nil
+}
+
+// Resolve a calendar path against the CalDAV server's base URL
+func caldavURL(manager *Manager, path string) (string, error) {
+	base, err := url.Parse(manager.config.CalDAV.URL)
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := url.Parse(path)
+	if err != nil {
+		return "", err
+	}
+
+	return base.ResolveReference(ref).String(), nil
+}
+
+// Send an authenticated request to the CalDAV server, returning an error for unsuccessful responses
+func caldavDo(config CalDAVConfig, manager *Manager, req *http.Request) (*http.Response, error) {
+	if manager.config.CalDAV.Username != "" {
+		req.SetBasicAuth(manager.config.CalDAV.Username, manager.config.CalDAV.Password)
+	}
+
+	resp, err := config.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+
+		return nil, fmt.Errorf("caldav request failed (status: %v, body: %v)", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return resp, nil
+}
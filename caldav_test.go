@@ -0,0 +1,239 @@
+package align_test
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/align"
+	"github.com/stretchr/testify/require"
+)
+
+const caldavTestConfig = `
+settings:
+  title: "Group Meetup"
+  interval: 7
+  offset: 2
+  timezone: "America/New_York"
+  contact_time: "0 10 * * 0"
+  deadline_time: "0 10 * * 1"
+
+caldav:
+  url: "%v"
+  username: "align"
+  password: "secret"
+  calendar: "/calendars/group/"
+
+persons:
+  - name: "Person 1"
+    request_method: "discord"
+    response_method: "discord"
+    id: "1"
+`
+
+func TestCalDAVPublish(t *testing.T) {
+	require := require.New(t)
+
+	// Record the event written to the server
+	var method, path, user, pass, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+
+		method, path, body = r.Method, r.URL.Path, string(data)
+		user, pass, _ = r.BasicAuth()
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	// Create a new manager using the test server
+	config := filepath.Join(t.TempDir(), "config.yml")
+	require.Nil(os.WriteFile(config, []byte(fmt.Sprintf(caldavTestConfig, server.URL)), 0o600))
+
+	manager, err := align.CreateManager("test-caldav", config, align.Options{
+		UseSQL: false,
+	})
+	require.Nil(err)
+
+	// Initialize the caldav module
+	align.InitCalDAV(manager, server.Client())
+
+	// Publish an event
+	err = align.CalDAVPublish(manager, align.Event{
+		UID:       "test-20240106@align",
+		Title:     "Group Meetup",
+		Date:      time.Date(2024, time.January, 6, 0, 0, 0, 0, time.UTC),
+		Attendees: []string{"Person 1"},
+	})
+	require.Nil(err)
+
+	require.Equal(http.MethodPut, method)
+	require.Equal("/calendars/group/test-20240106@align.ics", path)
+	require.Equal("align", user)
+	require.Equal("secret", pass)
+	require.Contains(body, "DTSTART;VALUE=DATE:20240106\r\n")
+}
+
+func TestCalDAVPublishError(t *testing.T) {
+	require := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	// Create a new manager using the test server
+	config := filepath.Join(t.TempDir(), "config.yml")
+	require.Nil(os.WriteFile(config, []byte(fmt.Sprintf(caldavTestConfig, server.URL)), 0o600))
+
+	manager, err := align.CreateManager("test-caldav", config, align.Options{
+		UseSQL: false,
+	})
+	require.Nil(err)
+
+	// Publishing without the module initialized fails
+	require.NotNil(align.CalDAVPublish(manager, align.Event{UID: "test"}))
+
+	// Unsuccessful responses are returned as errors
+	align.InitCalDAV(manager, server.Client())
+	require.ErrorContains(align.CalDAVPublish(manager, align.Event{UID: "test"}), "403")
+}
+
+func TestCalDAVBusy(t *testing.T) {
+	require := require.New(t)
+
+	// Answer calendar queries with one calendar and one calendar that can't be read
+	var method, path, depth, user, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+
+		method, path, depth, body = r.Method, r.URL.Path, r.Header.Get("Depth"), string(data)
+		user, _, _ = r.BasicAuth()
+
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?>
+<D:multistatus xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:response>
+    <D:href>/calendars/person2/dinner.ics</D:href>
+    <D:propstat>
+      <D:prop>
+        <C:calendar-data>BEGIN:VCALENDAR
+BEGIN:VEVENT
+DTSTART:20240113T230000Z
+DTEND:20240114T010000Z
+END:VEVENT
+BEGIN:VEVENT
+DTSTART;VALUE=DATE:20240110
+END:VEVENT
+END:VCALENDAR
+</C:calendar-data>
+      </D:prop>
+      <D:status>HTTP/1.1 200 OK</D:status>
+    </D:propstat>
+  </D:response>
+  <D:response>
+    <D:href>/calendars/person2/private.ics</D:href>
+    <D:propstat>
+      <D:prop>
+        <C:calendar-data/>
+      </D:prop>
+      <D:status>HTTP/1.1 403 Forbidden</D:status>
+    </D:propstat>
+  </D:response>
+</D:multistatus>`)
+	}))
+	defer server.Close()
+
+	config := fmt.Sprintf(caldavTestConfig, server.URL) + `
+  - name: "Person 2"
+    request_method: "discord"
+    response_method: "discord"
+    id: "2"
+    caldav: "/calendars/person2/"
+`
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.Nil(err)
+
+	manager := createClockTestManager(t, config, align.NewFakeClock(time.Date(2024, time.January, 7, 10, 0, 0, 0, loc)))
+	manager.ContactDay = sql.NullTime{Time: time.Date(2024, time.January, 7, 10, 0, 0, 0, loc), Valid: true}
+
+	// Reading without the module initialized fails
+	_, ok, err := manager.CalDAVBusy("Person 2")
+	require.True(ok)
+	require.NotNil(err)
+
+	align.InitCalDAV(manager, server.Client())
+
+	// Persons without a caldav calendar aren't busy
+	spans, ok, err := manager.CalDAVBusy("Person 1")
+	require.False(ok)
+	require.Nil(err)
+	require.Empty(spans)
+
+	// The events of the calendar are read, only querying the dates persons are asked about
+	spans, ok, err = manager.CalDAVBusy("Person 2")
+	require.True(ok)
+	require.Nil(err)
+
+	require.Equal("REPORT", method)
+	require.Equal("/calendars/person2/", path)
+	require.Equal("1", depth)
+	require.Equal("align", user)
+	require.Contains(body, `<C:time-range start="20240109T050000Z" end="20240116T050000Z"/>`)
+
+	require.Len(spans, 2)
+	require.True(time.Date(2024, time.January, 13, 18, 0, 0, 0, loc).Equal(spans[0].Start))
+	require.True(time.Date(2024, time.January, 13, 20, 0, 0, 0, loc).Equal(spans[0].End))
+	require.True(time.Date(2024, time.January, 10, 0, 0, 0, 0, loc).Equal(spans[1].Start))
+	require.True(time.Date(2024, time.January, 11, 0, 0, 0, 0, loc).Equal(spans[1].End))
+
+	// The busy spans are turned into busy dates
+	defaults, err := manager.CalendarDefaults("Person 2")
+	require.Nil(err)
+	require.Equal(map[string]bool{
+		"2024-01-09": true,
+		"2024-01-10": false,
+		"2024-01-11": true,
+		"2024-01-12": true,
+		"2024-01-13": false,
+		"2024-01-14": true,
+		"2024-01-15": true,
+	}, defaults)
+}
+
+func TestCalDAVBusyError(t *testing.T) {
+	require := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	config := fmt.Sprintf(caldavTestConfig, server.URL) + `
+  - name: "Person 2"
+    request_method: "discord"
+    response_method: "discord"
+    id: "2"
+    caldav: "/calendars/person2/"
+`
+
+	manager := createTestManager(t, config)
+	setContactDay(manager)
+	align.InitCalDAV(manager, server.Client())
+
+	// Unsuccessful responses are returned as errors
+	_, ok, err := manager.CalDAVBusy("Person 2")
+	require.True(ok)
+	require.ErrorContains(err, "401")
+
+	_, err = manager.CalendarDefaults("Person 2")
+	require.ErrorContains(err, "caldav")
+}
@@ -0,0 +1,446 @@
+package align
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* ---- TYPES ---- */
+
+// Event represents a chosen meetup that can be added to a calendar
+type Event struct {
+	UID       string    // Unique identifier of the event
+	Title     string    // The title of the event
+	Date      time.Time // The day the event takes place on
+	Start     time.Time // When the event starts in the group's timezone, or the zero time if it lasts all day
+	Attendees []string  // The names of the persons attending the event
+	Stamp     time.Time // When the event was created
+}
+
+// busy represents a span of time a person is busy according to their calendar
+type busy struct {
+	Start time.Time // When the person becomes busy
+	End   time.Time // When the person is free again
+}
+
+// icsEvent represents an event read from an iCalendar file
+type icsEvent struct {
+	Summary string    // The title of the event
+	Start   time.Time // When the event starts
+	End     time.Time // When the event ends
+	AllDay  bool      // Whether the event lasts for whole days
+	Free    bool      // Whether the event is marked as free (transparent) or cancelled
+}
+
+/* ---- GLOBALS ---- */
+
+// How the dates of all-day events are formatted in iCalendar files
+const icsDateFormat = "20060102"
+
+// How timestamps are formatted in iCalendar files
+const icsTimeFormat = "20060102T150405Z"
+
+// How local timestamps are formatted in iCalendar files
+const icsLocalTimeFormat = "20060102T150405"
+
+// Client used to download calendars
+var calendarClient = &http.Client{Timeout: 30 * time.Second}
+
+/* ---- FUNCTIONS ---- */
+
+// ICS formats the event as an iCalendar file
+func (e Event) ICS() []byte {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//ethanbaker//align//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"BEGIN:VEVENT",
+		"UID:" + icsEscape(e.UID),
+		"DTSTAMP:" + e.Stamp.UTC().Format(icsTimeFormat),
+	}
+
+	// Events with a start time have no set length, so they last until the end of their day
+	if e.Start.IsZero() {
+		lines = append(lines,
+			"DTSTART;VALUE=DATE:"+e.Date.Format(icsDateFormat),
+			"DTEND;VALUE=DATE:"+e.Date.AddDate(0, 0, 1).Format(icsDateFormat),
+		)
+	} else {
+		year, month, day := e.Start.Date()
+		lines = append(lines,
+			icsTimeProperty("DTSTART", e.Start),
+			icsTimeProperty("DTEND", time.Date(year, month, day+1, 0, 0, 0, 0, e.Start.Location())),
+		)
+	}
+
+	lines = append(lines, "SUMMARY:"+icsEscape(e.Title))
+
+	// Attendees don't have email addresses, so they are identified by name
+	for _, name := range e.Attendees {
+		cn := strings.ReplaceAll(name, `"`, "'")
+		lines = append(lines, fmt.Sprintf(`ATTENDEE;CN="%v";PARTSTAT=ACCEPTED:urn:align:%v`, cn, url.PathEscape(name)))
+	}
+
+	lines = append(lines, "END:VEVENT", "END:VCALENDAR")
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(icsFold(line))
+		b.WriteString("\r\n")
+	}
+
+	return []byte(b.String())
+}
+
+// Event returns the event for the best day of the last completion. Returns false if no day was found
+func (m *Manager) Event() (Event, bool) {
+	m.edit.Lock()
+	r := m.LastResult
+	m.edit.Unlock()
+
+	if r == nil || len(r.Days) == 0 {
+		return Event{}, false
+	}
+
+	return m.newEvent(r.Days[0]), true
+}
+
+// CalendarHandler returns an HTTP handler that serves the event for the last completion as an iCalendar file
+func (m *Manager) CalendarHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event, ok := m.Event()
+		if !ok {
+			http.Error(w, "no event has been scheduled", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="align.ics"`)
+		if _, err := w.Write(event.ICS()); err != nil {
+			log.Printf("[ERR]: error serving calendar (err: %v)\n", err)
+		}
+	})
+}
+
+// Create an event for an available day
+func (m *Manager) newEvent(d day) Event {
+	event := Event{
+		UID:       fmt.Sprintf("%v-%v@align", url.PathEscape(m.Name), d.Date.Format(icsDateFormat)),
+		Title:     m.config.Title,
+		Date:      d.Date,
+		Attendees: d.AvailablePersons,
+		Stamp:     m.clock.Now(),
+	}
+
+	if m.config.EventTime != "" {
+		event.Start = m.eventStart(event)
+	}
+
+	return event
+}
+
+// Format a timestamp as an iCalendar property, in the timestamp's timezone if it is one readers can look up by name
+func icsTimeProperty(name string, t time.Time) string {
+	if loc := t.Location(); loc != time.UTC && loc != time.Local && loc.String() != "" {
+		return fmt.Sprintf("%v;TZID=%v:%v", name, loc, t.Format(icsLocalTimeFormat))
+	}
+
+	return name + ":" + t.UTC().Format(icsTimeFormat)
+}
+
+// Escape text so it can be used as an iCalendar value
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// Unescape an iCalendar text value
+func icsUnescape(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
+
+// Fold an iCalendar line so no line is longer than 75 octets, without splitting characters
+func icsFold(line string) string {
+	var b strings.Builder
+
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > 75 {
+			b.WriteString("\r\n ")
+			length = 1
+		}
+
+		b.WriteRune(r)
+		length += size
+	}
+
+	return b.String()
+}
+
+// Generate a person's default availability from their calendars, where every date they aren't busy on is available.
+// Returns nil if the person has no calendar
+func (m *Manager) calendarDefaults(person Person) (map[string]bool, error) {
+	// Collect busy spans from every calendar the person uses
+	spans := []busy{}
+	found := false
+	for name, calendar := range calendars {
+		s, ok, err := calendar(person, m)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %v calendar (err: %v)", name, err)
+		}
+
+		spans = append(spans, s...)
+		found = found || ok
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	// A date is busy if any busy span overlaps it
+	availability := map[string]bool{}
+	for _, date := range m.generateDates() {
+		end := date.AddDate(0, 0, 1)
+
+		free := true
+		for _, span := range spans {
+			if span.Start.Before(end) && span.End.After(date) {
+				free = false
+				break
+			}
+		}
+
+		availability[date.Format(DATE_FORMAT)] = free
+	}
+
+	return availability, nil
+}
+
+// Read the busy spans from a person's iCalendar URL or file. Returns false if the person has no calendar
+func icsBusy(person Person, m *Manager) ([]busy, bool, error) {
+	if person.Calendar == "" {
+		return nil, false, nil
+	}
+
+	log.Printf("[INFO]: reading calendar for '%v'\n", person.Name)
+
+	data, err := readCalendar(person.Calendar)
+	if err != nil {
+		return nil, true, err
+	}
+
+	spans, err := parseBusy(data, m.loc)
+	return spans, true, err
+}
+
+// Read a calendar from a URL or a local file
+func readCalendar(location string) ([]byte, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return os.ReadFile(location)
+	}
+
+	resp, err := calendarClient.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot download calendar (status: %v)", resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// Parse the spans of time that events in an iCalendar file take up. Events marked as transparent or cancelled don't
+// make a person busy. Recurring events only count for their first occurrence
+func parseBusy(data []byte, loc *time.Location) ([]busy, error) {
+	events, err := parseEvents(data, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	spans := []busy{}
+	for _, event := range events {
+		if !event.Free {
+			spans = append(spans, busy{Start: event.Start, End: event.End})
+		}
+	}
+
+	return spans, nil
+}
+
+// Parse the events in an iCalendar file. Events without a start are skipped
+func parseEvents(data []byte, loc *time.Location) ([]icsEvent, error) {
+	// Unfold lines that were split over multiple lines
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\n ", "")
+	text = strings.ReplaceAll(text, "\n\t", "")
+
+	events := []icsEvent{}
+
+	var summary string
+	var start, end time.Time
+	var duration time.Duration
+	var allDay, inEvent, free bool
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+
+		// Split the line into its name, parameters and value
+		head, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		params := strings.Split(head, ";")
+		name := strings.ToUpper(params[0])
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			summary, start, end, duration = "", time.Time{}, time.Time{}, 0
+			allDay, inEvent, free = false, true, false
+
+		case name == "END" && value == "VEVENT":
+			inEvent = false
+			if start.IsZero() {
+				continue
+			}
+
+			// Events without an end last for their duration, or for the whole day if they are all-day events
+			if end.IsZero() {
+				switch {
+				case duration > 0:
+					end = start.Add(duration)
+				case allDay:
+					end = start.AddDate(0, 0, 1)
+				default:
+					end = start
+				}
+			}
+
+			events = append(events, icsEvent{Summary: summary, Start: start, End: end, AllDay: allDay, Free: free})
+
+		case !inEvent:
+			continue
+
+		case name == "SUMMARY":
+			summary = icsUnescape(value)
+
+		case name == "DTSTART":
+			t, date, err := parseICSTime(value, params[1:], loc)
+			if err != nil {
+				return nil, err
+			}
+			start, allDay = t, date
+
+		case name == "DTEND":
+			t, _, err := parseICSTime(value, params[1:], loc)
+			if err != nil {
+				return nil, err
+			}
+			end = t
+
+		case name == "DURATION":
+			d, err := parseICSDuration(value)
+			if err != nil {
+				return nil, err
+			}
+			duration = d
+
+		case name == "TRANSP":
+			free = free || strings.EqualFold(value, "TRANSPARENT")
+
+		case name == "STATUS":
+			free = free || strings.EqualFold(value, "CANCELLED")
+		}
+	}
+
+	return events, nil
+}
+
+// Parse an iCalendar date or date-time value. Returns whether the value was a date without a time
+func parseICSTime(value string, params []string, loc *time.Location) (time.Time, bool, error) {
+	// Find the timezone the value is in, if it isn't in UTC
+	for _, param := range params {
+		key, v, _ := strings.Cut(param, "=")
+		if strings.EqualFold(key, "TZID") {
+			if l, err := time.LoadLocation(strings.Trim(v, `"`)); err == nil {
+				loc = l
+			}
+		}
+	}
+
+	if len(value) == len(icsDateFormat) {
+		t, err := time.ParseInLocation(icsDateFormat, value, loc)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icsTimeFormat, value)
+		return t, false, err
+	}
+
+	t, err := time.ParseInLocation(icsLocalTimeFormat, value, loc)
+	return t, false, err
+}
+
+// Parse an iCalendar duration value, such as 'PT1H30M' or 'P1D'
+func parseICSDuration(value string) (time.Duration, error) {
+	original := value
+
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimLeft(value, "+-")
+
+	value, ok := strings.CutPrefix(value, "P")
+	if !ok {
+		return 0, fmt.Errorf("invalid duration '%v'", original)
+	}
+
+	units := map[byte]time.Duration{
+		'W': 7 * 24 * time.Hour,
+		'D': 24 * time.Hour,
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+	}
+
+	var duration time.Duration
+	number := ""
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+
+		switch {
+		case c == 'T':
+			continue
+		case c >= '0' && c <= '9':
+			number += string(c)
+		default:
+			unit, ok := units[c]
+			if !ok || number == "" {
+				return 0, fmt.Errorf("invalid duration '%v'", original)
+			}
+
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				return 0, err
+			}
+
+			duration += time.Duration(n) * unit
+			number = ""
+		}
+	}
+
+	if negative {
+		duration = -duration
+	}
+
+	return duration, nil
+}
@@ -0,0 +1,272 @@
+package align_test
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/align"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventICS(t *testing.T) {
+	require := require.New(t)
+
+	event := align.Event{
+		UID:       "test-20240106@align",
+		Title:     "Game night; bring snacks, drinks",
+		Date:      time.Date(2024, time.January, 6, 0, 0, 0, 0, time.UTC),
+		Attendees: []string{"Person 1", "Person \"2\""},
+		Stamp:     time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	ics := string(event.ICS())
+
+	// Every line must end with CRLF
+	require.True(strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+	require.NotContains(strings.ReplaceAll(ics, "\r\n", ""), "\n")
+
+	// The event is an all-day event on the chosen date
+	require.Contains(ics, "BEGIN:VEVENT\r\n")
+	require.Contains(ics, "UID:test-20240106@align\r\n")
+	require.Contains(ics, "DTSTAMP:20240101T100000Z\r\n")
+	require.Contains(ics, "DTSTART;VALUE=DATE:20240106\r\n")
+	require.Contains(ics, "DTEND;VALUE=DATE:20240107\r\n")
+
+	// Text values are escaped
+	require.Contains(ics, `SUMMARY:Game night\; bring snacks\, drinks`+"\r\n")
+
+	// Attendees are listed by name
+	require.Contains(ics, `ATTENDEE;CN="Person 1";PARTSTAT=ACCEPTED:urn:align:Person%201`+"\r\n")
+	require.Contains(ics, `ATTENDEE;CN="Person '2'";PARTSTAT=ACCEPTED:urn:align:Person%20%222%22`+"\r\n")
+}
+
+func TestEventICSFolding(t *testing.T) {
+	require := require.New(t)
+
+	event := align.Event{
+		UID:   "fold@align",
+		Title: strings.Repeat("ä", 100),
+		Date:  time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	// No line may be longer than 75 octets, and folded lines start with a space
+	lines := strings.Split(strings.TrimSuffix(string(event.ICS()), "\r\n"), "\r\n")
+	unfolded := ""
+	for _, line := range lines {
+		require.LessOrEqual(len(line), 75)
+
+		if strings.HasPrefix(line, " ") {
+			unfolded += line[1:]
+		} else {
+			unfolded += "\n" + line
+		}
+	}
+
+	require.Contains(unfolded, "\nSUMMARY:"+strings.Repeat("ä", 100)+"\n")
+}
+
+func TestEventICSTime(t *testing.T) {
+	require := require.New(t)
+
+	manager := createTestManager(t, withSettings(managerTestConfig, "  event_time: \"18:30\"\n"))
+	setContactDay(manager)
+	require.Nil(manager.Decide("2024-01-11"))
+
+	// Events with a start time start at it in the group's timezone, and last until the end of the day
+	ics := string(manager.Decision.ICS())
+	require.Contains(ics, "DTSTART;TZID=America/New_York:20240111T183000\r\n")
+	require.Contains(ics, "DTEND;TZID=America/New_York:20240112T000000\r\n")
+	require.NotContains(ics, "VALUE=DATE")
+
+	// The event starts when persons are told it does
+	require.Equal("Thursday 01/11 at 18:30", manager.EventWhen(*manager.Decision, "Person 1"))
+
+	// Timezones readers can't look up by name are written as UTC
+	event := align.Event{Start: time.Date(2024, time.January, 11, 18, 30, 0, 0, time.FixedZone("", -5*60*60))}
+	ics = string(event.ICS())
+	require.Contains(ics, "DTSTART:20240111T233000Z\r\n")
+	require.Contains(ics, "DTEND:20240112T050000Z\r\n")
+}
+
+func TestParseICSTime(t *testing.T) {
+	require := require.New(t)
+
+	ny, err := time.LoadLocation("America/New_York")
+	require.Nil(err)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.Nil(err)
+
+	tests := []struct {
+		name   string
+		value  string
+		params []string
+		time   time.Time
+		allDay bool
+	}{
+		{"date", "20240110", nil, time.Date(2024, time.January, 10, 0, 0, 0, 0, ny), true},
+		{"date with tzid", "20240110", []string{"VALUE=DATE", "TZID=Europe/Berlin"}, time.Date(2024, time.January, 10, 0, 0, 0, 0, berlin), true},
+		{"local time", "20240115T090000", nil, time.Date(2024, time.January, 15, 9, 0, 0, 0, ny), false},
+		{"time with tzid", "20240112T030000", []string{"TZID=Europe/Berlin"}, time.Date(2024, time.January, 12, 3, 0, 0, 0, berlin), false},
+		{"time with quoted tzid", "20240112T030000", []string{`TZID="Europe/Berlin"`}, time.Date(2024, time.January, 12, 3, 0, 0, 0, berlin), false},
+		{"time with unknown tzid", "20240112T030000", []string{"TZID=Nowhere"}, time.Date(2024, time.January, 12, 3, 0, 0, 0, ny), false},
+		{"utc time", "20240113T230000Z", nil, time.Date(2024, time.January, 13, 23, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, test := range tests {
+		parsed, allDay, err := align.ParseICSTime(test.value, test.params, ny)
+		require.Nil(err, test.name)
+		require.True(test.time.Equal(parsed), "%v: %v", test.name, parsed)
+		require.Equal(test.time.Location().String(), parsed.Location().String(), test.name)
+		require.Equal(test.allDay, allDay, test.name)
+	}
+
+	// Values that aren't dates or times fail to parse
+	_, _, err = align.ParseICSTime("tomorrow", nil, ny)
+	require.NotNil(err)
+}
+
+func TestParseICSDuration(t *testing.T) {
+	require := require.New(t)
+
+	tests := []struct {
+		value    string
+		duration time.Duration
+	}{
+		{"PT1H30M", 90 * time.Minute},
+		{"P1D", 24 * time.Hour},
+		{"P1W", 7 * 24 * time.Hour},
+		{"P1DT2H3M4S", 26*time.Hour + 3*time.Minute + 4*time.Second},
+		{"+PT15M", 15 * time.Minute},
+		{"-PT15M", -15 * time.Minute},
+	}
+
+	for _, test := range tests {
+		duration, err := align.ParseICSDuration(test.value)
+		require.Nil(err, test.value)
+		require.Equal(test.duration, duration, test.value)
+	}
+
+	for _, value := range []string{"", "1H", "PTH", "PT1X"} {
+		_, err := align.ParseICSDuration(value)
+		require.NotNil(err, value)
+	}
+}
+
+func TestParseBusy(t *testing.T) {
+	require := require.New(t)
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.Nil(err)
+
+	data, err := os.ReadFile(filepath.Join("testdata", "calendar.ics"))
+	require.Nil(err)
+
+	spans, err := align.ParseBusy(data, loc)
+	require.Nil(err)
+
+	// Transparent and cancelled events don't make a person busy
+	tests := []struct {
+		name       string
+		start, end time.Time
+	}{
+		{"all-day", time.Date(2024, time.January, 10, 0, 0, 0, 0, loc), time.Date(2024, time.January, 11, 0, 0, 0, 0, loc)},
+		{"tzid", time.Date(2024, time.January, 11, 21, 0, 0, 0, loc), time.Date(2024, time.January, 11, 23, 0, 0, 0, loc)},
+		{"utc", time.Date(2024, time.January, 13, 18, 0, 0, 0, loc), time.Date(2024, time.January, 13, 20, 0, 0, 0, loc)},
+		{"duration", time.Date(2024, time.January, 15, 9, 0, 0, 0, loc), time.Date(2024, time.January, 15, 10, 30, 0, 0, loc)},
+	}
+
+	require.Len(spans, len(tests))
+	for i, test := range tests {
+		require.True(test.start.Equal(spans[i].Start), "%v: %v", test.name, spans[i].Start)
+		require.True(test.end.Equal(spans[i].End), "%v: %v", test.name, spans[i].End)
+	}
+
+	// Broken values are returned as errors
+	_, err = align.ParseBusy([]byte("BEGIN:VEVENT\nDTSTART:tomorrow\nEND:VEVENT\n"), loc)
+	require.NotNil(err)
+}
+
+func TestReadCalendar(t *testing.T) {
+	require := require.New(t)
+
+	path := filepath.Join("testdata", "calendar.ics")
+	file, err := os.ReadFile(path)
+	require.Nil(err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/calendar.ics" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write(file)
+	}))
+	defer server.Close()
+
+	// Calendars are read from files and URLs
+	data, err := align.ReadCalendar(path)
+	require.Nil(err)
+	require.Equal(file, data)
+
+	data, err = align.ReadCalendar(server.URL + "/calendar.ics")
+	require.Nil(err)
+	require.Equal(file, data)
+
+	// Missing calendars are returned as errors
+	_, err = align.ReadCalendar(filepath.Join("testdata", "missing.ics"))
+	require.NotNil(err)
+
+	_, err = align.ReadCalendar(server.URL + "/missing.ics")
+	require.ErrorContains(err, "404")
+}
+
+func TestCalendarDefaults(t *testing.T) {
+	require := require.New(t)
+
+	config := managerTestConfig + `
+  - name: "Person 2"
+    request_method: "discord"
+    response_method: "discord"
+    id: "2"
+    calendar: "` + filepath.Join("testdata", "calendar.ics") + `"
+  - name: "Person 3"
+    request_method: "discord"
+    response_method: "discord"
+    id: "3"
+    calendar: "` + filepath.Join("testdata", "missing.ics") + `"
+`
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.Nil(err)
+
+	manager := createClockTestManager(t, config, align.NewFakeClock(time.Date(2024, time.January, 7, 10, 0, 0, 0, loc)))
+	manager.ContactDay = sql.NullTime{Time: time.Date(2024, time.January, 7, 10, 0, 0, 0, loc), Valid: true}
+
+	// Persons without a calendar have no defaults
+	defaults, err := manager.CalendarDefaults("Person 1")
+	require.Nil(err)
+	require.Nil(defaults)
+
+	// Dates are busy if any event overlaps them in the manager's timezone
+	defaults, err = manager.CalendarDefaults("Person 2")
+	require.Nil(err)
+	require.Equal(map[string]bool{
+		"2024-01-09": true,
+		"2024-01-10": false,
+		"2024-01-11": false,
+		"2024-01-12": true,
+		"2024-01-13": false,
+		"2024-01-14": true,
+		"2024-01-15": false,
+	}, defaults)
+
+	// Calendars that can't be read are returned as errors
+	_, err = manager.CalendarDefaults("Person 3")
+	require.NotNil(err)
+}
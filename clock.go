@@ -0,0 +1,148 @@
+package align
+
+import (
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+/* ---- TYPES ---- */
+
+// Clock tells a manager what time it is and runs the manager's jobs, such as contacting persons or sending reminders,
+// when they are due. Managers use the system clock unless another clock is given in the options
+type Clock interface {
+	Now() time.Time                                             // The current time
+	Schedule(schedule cron.Schedule, job cron.Job) cron.EntryID // Run a job every time the schedule is due
+	Remove(id cron.EntryID)                                     // Stop running a scheduled job
+}
+
+// cronClock is the system clock, running jobs on a cron service
+type cronClock struct {
+	*cron.Cron
+}
+
+// FakeClock is a clock that only moves when it is told to, so time-dependent behavior can be tested. Jobs run as the
+// clock passes their time, in the order they are due
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	count   cron.EntryID
+	entries map[cron.EntryID]*fakeClockEntry
+}
+
+// fakeClockEntry represents a job scheduled on a fake clock
+type fakeClockEntry struct {
+	schedule cron.Schedule // When the job runs
+	job      cron.Job      // The job to run
+	next     time.Time     // The next time the job runs, or the zero time if it doesn't run again
+}
+
+/* ---- FUNCTIONS ---- */
+
+// Create a clock on the system time, running jobs with cron schedules in the given timezone
+func newCronClock(loc *time.Location) Clock {
+	c := cron.New(cron.WithLocation(loc))
+	c.Start()
+
+	return cronClock{Cron: c}
+}
+
+// Parse a cron spec such as '0 10 * * 0'. Specs run in the given timezone, unless they set their own with 'CRON_TZ='
+func parseSpec(spec string, loc *time.Location) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if s, ok := schedule.(*cron.SpecSchedule); ok && s.Location == time.Local {
+		s.Location = loc
+	}
+
+	return schedule, nil
+}
+
+// Now returns the system time
+func (c cronClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewFakeClock creates a fake clock set to the given time
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now:     now,
+		entries: make(map[cron.EntryID]*fakeClockEntry),
+	}
+}
+
+// Now returns the time the clock is set to
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Schedule runs a job every time the schedule is due as the clock is moved
+func (c *FakeClock) Schedule(schedule cron.Schedule, job cron.Job) cron.EntryID {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.count++
+	c.entries[c.count] = &fakeClockEntry{schedule: schedule, job: job, next: schedule.Next(c.now)}
+
+	return c.count
+}
+
+// Remove stops running a scheduled job
+func (c *FakeClock) Remove(id cron.EntryID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, id)
+}
+
+// Advance moves the clock forward, running every job that is due along the way. Jobs run one at a time, so messages
+// they send have been sent once Advance returns
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock forward to a point in time, running every job that is due along the way. The clock can't be
+// moved back
+func (c *FakeClock) Set(t time.Time) {
+	for {
+		c.mu.Lock()
+
+		// Find the job that is due first, with jobs due at the same time run in the order they were scheduled
+		var id cron.EntryID
+		var entry *fakeClockEntry
+		for i, e := range c.entries {
+			if e.next.IsZero() || e.next.After(t) {
+				continue
+			}
+
+			if entry == nil || e.next.Before(entry.next) || (e.next.Equal(entry.next) && i < id) {
+				id, entry = i, e
+			}
+		}
+
+		if entry == nil {
+			if t.After(c.now) {
+				c.now = t
+			}
+			c.mu.Unlock()
+			return
+		}
+
+		c.now = entry.next
+		entry.next = entry.schedule.Next(c.now)
+		if entry.next.IsZero() {
+			delete(c.entries, id)
+		}
+		c.mu.Unlock()
+
+		// Jobs can use the clock themselves, so they run without holding its lock
+		entry.job.Run()
+	}
+}
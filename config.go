@@ -9,22 +9,69 @@ type DSN struct {
 	DBName string `yaml:"dbname"`
 }
 
+// CalDAV represents credentials for a CalDAV server
+type CalDAV struct {
+	URL      string `yaml:"url"`      // The base URL of the server
+	Username string `yaml:"username"` // The username to log in with
+	Password string `yaml:"password"` // The password to log in with
+	Calendar string `yaml:"calendar"` // The shared calendar the chosen event is written to
+}
+
 // Person represents a contactable person who provides feedback on what days they are free
 type Person struct {
 	Name           string `yaml:"name"`            // The person's name
 	RequestMethod  string `yaml:"request_method"`  // The person's ideal contact method for availability requests
 	ResponseMethod string `yaml:"response_method"` // The person's ideal contact method for responses
 	ID             string `yaml:"id"`              // The person's ID used to contact them with a given method
+	Calendar       string `yaml:"calendar"`        // An optional iCalendar URL or file used to pre-fill the person's availability
+	CalDAV         string `yaml:"caldav"`          // An optional CalDAV calendar used to pre-fill the person's availability
+	Organizer      bool   `yaml:"organizer"`       // Whether the person picks the final day before results are broadcast
+	Timezone       string `yaml:"timezone"`        // An optional timezone the person lives in, if it differs from the group's
+	ContactTime    string `yaml:"contact_time"`    // An optional cron string for when the person is contacted, if it differs from the group's
+	QuietHours     string `yaml:"quiet_hours"`     // Optional hours the person is never messaged in, such as '22:00-08:00'
+}
+
+// Templates represent text/template messages that replace the default messages of a group. Templates are given a
+// MessageData with the values of the message
+type Templates struct {
+	Header      string `yaml:"header"`       // The header of availability requests
+	RequestBody string `yaml:"request_body"` // The list of dates persons react to on discord
+	Reminder    string `yaml:"reminder"`     // Reminders of the decided event
+	Result      string `yaml:"result"`       // The results of a schedule
+	NoResult    string `yaml:"no_result"`    // The results of a schedule no day works for
 }
 
 // Settings represent general configuration settings
 type Settings struct {
-	Title           string `yaml:"title"`         // The title of the group
-	Interval        int    `yaml:"interval"`      // How many days to get availability for each cycle
-	Offset          int    `yaml:"offset"`        // How many days after the contact date should availability gathering start
-	ContactTimezone string `yaml:"timezone"`      // The timezone in which to contact persons
-	ContactTime     string `yaml:"contact_time"`  // A cron string that shows when the persons should be contacted
-	DeadlineTime    string `yaml:"deadline_time"` // A cron string that shows when the final decision should be made
+	Title           string    `yaml:"title"`           // The title of the group
+	Locale          string    `yaml:"locale"`          // The language messages are sent in, such as 'de', 'es' or 'fr'
+	Interval        int       `yaml:"interval"`        // How many days to get availability for each cycle
+	Offset          int       `yaml:"offset"`          // How many days after the contact date should availability gathering start
+	Range           string    `yaml:"range"`           // An optional window of dates replacing the interval and offset, such as 'next week'
+	Weekdays        []string  `yaml:"weekdays"`        // The weekdays persons are asked about, or every weekday if empty
+	Exclude         []string  `yaml:"exclude"`         // Dates and date ranges persons are never asked about, such as '2024-12-24..2024-12-31'
+	Holidays        string    `yaml:"holidays"`        // An optional iCalendar or YAML file of holidays persons are never asked about
+	ContactTimezone string    `yaml:"timezone"`        // The timezone in which to contact persons
+	ContactTime     string    `yaml:"contact_time"`    // A cron string that shows when the persons should be contacted
+	DeadlineTime    string    `yaml:"deadline_time"`   // A cron string that shows when the final decision should be made
+	QuietHours      string    `yaml:"quiet_hours"`     // Hours persons are never messaged in, such as '22:00-08:00'
+	AttachCalendar  bool      `yaml:"attach_calendar"` // Whether results should include an iCalendar file for the best day
+	Confirm         bool      `yaml:"confirm"`         // Whether persons are asked to confirm once a day is decided on
+	AutoPick        bool      `yaml:"auto_pick"`       // Whether the best day is decided on automatically
+	EventTime       string    `yaml:"event_time"`      // The time the decided event starts at, formatted as '15:04'
+	Reminders       []string  `yaml:"reminders"`       // How long before the decided event reminders are sent, such as '1 day before'
+	Templates       Templates `yaml:"templates"`       // Templates replacing the default messages
+}
+
+// DiscordSettings represent configuration settings for the discord module
+type DiscordSettings struct {
+	ChannelID string `yaml:"channel_id"` // The guild channel schedules are posted in for 'discord_channel' persons
+	Thread    bool   `yaml:"thread"`     // Whether channel results should be posted in a thread off of the schedule
+}
+
+// TelegramSettings represent configuration settings for the telegram module
+type TelegramSettings struct {
+	ChatID int64 `yaml:"chat_id"` // The group chat polls are posted in for 'telegram_group' persons
 }
 
 // Config represents the configuration align will run off of
@@ -35,6 +82,13 @@ type Config struct {
 	// SQL Credentials
 	Dsn *DSN `yaml:"sql,omitempty"`
 
+	// CalDAV Credentials
+	CalDAV *CalDAV `yaml:"caldav,omitempty"`
+
 	// Application configuration settings
 	Settings `yaml:"settings"`
+
+	// Module configuration settings
+	Discord  DiscordSettings  `yaml:"discord,omitempty"`
+	Telegram TelegramSettings `yaml:"telegram,omitempty"`
 }
@@ -1,10 +1,10 @@
 package align
 
 import (
+	"bytes"
 	"fmt"
 	"log"
 	"strings"
-	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"gorm.io/gorm"
@@ -12,28 +12,80 @@ import (
 
 /* ---- TYPES ---- */
 
+// DiscordSession represents the discord API calls align makes. It is implemented by *discordgo.Session, and can be
+// replaced by a fake to run align without connecting to discord
+type DiscordSession interface {
+	AddHandler(handler interface{}) func()
+	ApplicationCommandCreate(appID string, guildID string, cmd *discordgo.ApplicationCommand, options ...discordgo.RequestOption) (*discordgo.ApplicationCommand, error)
+	UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageEditComplex(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	MessageReactionAdd(channelID string, messageID string, emojiID string, options ...discordgo.RequestOption) error
+	MessageReactions(channelID string, messageID string, emojiID string, limit int, beforeID string, afterID string, options ...discordgo.RequestOption) ([]*discordgo.User, error)
+	MessageThreadStart(channelID string, messageID string, name string, archiveDuration int, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error
+	FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error)
+}
+
 // DiscordConfig holds all necessary fields for discord request/response functions to run successfully
 type DiscordConfig struct {
-	Session *discordgo.Session
+	Session DiscordSession
+
+	removeHandler func() // Removes the interaction handler added for the manager
 }
 
 type discordEntry struct {
 	gorm.Model
 
 	Person    string // The person's name this entry is related to
+	Mode      string // The request mode this entry was sent with
 	Index     int    // The index of this entry
 	ChannelID string // The discord channel ID this entry represents
 	MessageID string // The discord message ID this entry represents
+	Selection string // The person's component selection, with one state character per date
 
 	// The manager this entry is related to
 	Manager   *Manager
 	ManagerID *int
 }
 
+// Modes a discord entry can be sent with
+const (
+	discordModeDirect     = ""           // Entry sent in a DM to a single person
+	discordModeChannel    = "channel"    // Entry shared by all persons in a guild channel
+	discordModeComponents = "components" // Entry with select menus sent in a DM to a single person
+)
+
+// States a date can have in a component selection
+const (
+	discordStateNo    = 'n'
+	discordStateYes   = 'y'
+	discordStateMaybe = 'm'
+)
+
+// How many dates are shown on a single select menu (limited by discord)
+const discordMenuSize = 25
+
+// How many fields a single embed can have (limited by discord)
+const discordEmbedFields = 25
+
+// How many characters the value of an embed field can have (limited by discord)
+const discordFieldLength = 1024
+
+// Colors of result embeds
+const (
+	discordResultColor   = 0x57f287 // A day works for the group
+	discordNoResultColor = 0xed4245 // No day works for the group
+)
+
 /* ---- GLOBALS ---- */
 
 var discordEntries []*discordEntry
 
+// Escapes the characters discord uses for markdown
+var discordEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`, ">", `\>`)
+
 var emojis = []string{
 	"1️⃣",
 	"2️⃣",
@@ -44,50 +96,116 @@ var emojis = []string{
 	"7️⃣",
 }
 
-const discordRequestHeader = `⬜⬜⬜⬜⬜⬜⬜⬜⬜⬜⬜⬜⬜⬜
-
-**Schedule for %v**`
-
-const discordRequestBody = `%v
-❌ - None
-
-React with the corresponding emoji for dates you are free
-`
-
-const discordResponseBody = `⬜⬜⬜⬜⬜⬜⬜⬜⬜⬜⬜⬜⬜⬜
-
-**Schedule results for %v**
-
-%v/%v people available
+// Keys of the discord messages in the locale catalogs
+const (
+	discordRequestHeader        = "discord_request_header"
+	discordChannelRequestHeader = "discord_channel_request_header"
+	discordRequestBody          = "discord_request_body"
+	discordCalendarNote         = "discord_calendar_note"
+	discordComponentsBody       = "discord_components_body"
+	discordStatusBody           = "discord_status_body"
+	discordResultTitle          = "discord_result_title"
+	discordResultAvailable      = "discord_result_available"
+	discordAnnouncementBody     = "discord_announcement_body"
+	discordRSVPBody             = "discord_rsvp_body"
+	discordReminderBody         = "discord_reminder_body"
+	discordApprovalBody         = "discord_approval_body"
+	discordEventBody            = "discord_event_body"
+)
 
-%v%v%v
-⬜⬜⬜⬜⬜⬜⬜⬜⬜⬜⬜⬜⬜⬜
-`
+// The '/align' slash command persons use to manage their own schedule
+var discordCommand = &discordgo.ApplicationCommand{
+	Name:        "align",
+	Description: "Manage your availability for the current schedule",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "status",
+			Description: "See what you answered and who hasn't answered yet",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "availability",
+			Description: "Change your availability",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "optout",
+			Description: "Opt out of the current schedule",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "results",
+			Description: "See the results of the last schedule",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "event",
+			Description: "See who is going to the decided event",
+		},
+	},
+}
 
 /* ---- FUNCTIONS ---- */
 
-// Initialize a discord config
-func InitDiscord(manager *Manager, s *discordgo.Session) {
+// Initialize a discord config. The application ID is the ID of the bot's user (session.State.User.ID once the session
+// is open), which the '/align' slash command is registered for
+func InitDiscord(manager *Manager, s DiscordSession, appID string) {
 	log.Println("[INFO]: initializing discord config")
 
+	// Listen for interactions with align's components, replacing the listener of any previous initialization of the
+	// manager. Other managers sharing the session keep their own listeners
+	if previous, ok := manager.moduleConfigs["discord"].(DiscordConfig); ok && previous.removeHandler != nil {
+		previous.removeHandler()
+	}
+
 	manager.moduleConfigs["discord"] = DiscordConfig{
 		Session: s,
+		removeHandler: s.AddHandler(func(_ *discordgo.Session, i *discordgo.InteractionCreate) {
+			switch i.Type {
+			case discordgo.InteractionMessageComponent:
+				discordHandleComponent(manager, s, i)
+			case discordgo.InteractionApplicationCommand:
+				discordHandleCommand(manager, s, i)
+			}
+		}),
 	}
 
+	// Register the '/align' slash command
+	if appID != "" {
+		log.Println("[INFO]: registering discord slash commands")
+
+		if _, err := s.ApplicationCommandCreate(appID, "", discordCommand); err != nil {
+			log.Printf("[ERR]: cannot register discord slash commands (err: %v)\n", err)
+		}
+	} else {
+		log.Println("[WARN]: no discord application ID was given, slash commands will not be registered")
+	}
+
+	// Send the reminders and held messages that were missed while align wasn't running, once every module is
+	// initialized
+	manager.resume()
+
 	if !manager.options.UseSQL {
 		return
 	}
 
-	// If using SQL, populate discord entries
-	if !manager.db.Migrator().HasTable(&discordEntry{}) {
-		if err := manager.db.AutoMigrate(&discordEntry{}); err != nil {
-			log.Fatalf("[ERR]: cannot migrate discordEntry object (err: %v)\n", err)
-		}
+	// If using SQL, populate discord entries. The table is migrated every time so columns added since it was created
+	// are added to it
+	if err := manager.db.AutoMigrate(&discordEntry{}); err != nil {
+		log.Fatalf("[ERR]: cannot migrate discordEntry object (err: %v)\n", err)
 	}
 
-	if err := manager.db.Model(&discordEntry{}).Where("id = ?", fmt.Sprint(manager.ID)).Find(&discordEntries).Error; err != nil {
+	if err := manager.db.Model(&discordEntry{}).Where("manager_id = ?", manager.ID).Find(&discordEntries).Error; err != nil {
 		log.Fatalf("[ERR]: cannot read discord entries from database (err: %v)\n", err)
 	}
+
+	// Restore selections made on components
+	for _, entry := range discordEntries {
+		if entry.Mode == discordModeComponents {
+			manager.discordApplySelection(entry)
+		}
+	}
 }
 
 // Request an availability schedule using discord
@@ -105,18 +223,59 @@ func DiscordRequest(person Person, manager *Manager) error {
 		return fmt.Errorf("discord session is nil")
 	}
 
-	log.Println("[INFO]: generating availability dates")
+	log.Printf("[INFO]: opening discord channel to id '%v'\n", person.ID)
 
-	// Generate all dates in the availability map
-	year, month, day := manager.ContactDay.Time.Date()
-	today := time.Date(year, month, day, 0, 0, 0, 0, manager.loc)
+	// Create a private channel to DM the user
+	channel, err := config.Session.UserChannelCreate(person.ID)
+	if err != nil {
+		return err
+	}
+
+	log.Println("[INFO]: sending discord header")
+
+	// Send the header message
+	_, err = config.Session.ChannelMessageSend(channel.ID, manager.requestHeader(discordRequestHeader, manager.location(person), ""))
+	if err != nil {
+		return err
+	}
 
-	dates := []string{}
-	for day := manager.config.Offset; day < manager.config.Interval+manager.config.Offset; day++ {
-		timestamp := today.Add(time.Duration(DAY_DURATION * day)).Format(TIME_FORMAT)
-		dates = append(dates, timestamp)
+	// Let the person know their calendar was used to pre-fill their answer
+	defaults := manager.loadDefaults(person)
+	if defaults != nil {
+		if _, err = config.Session.ChannelMessageSend(channel.ID, manager.text(discordCalendarNote)); err != nil {
+			return err
+		}
+	}
+
+	return discordSendSchedule(config, manager, channel.ID, person.Name, discordModeDirect, defaults)
+}
+
+// Request an availability schedule using discord select menus
+func DiscordComponentsRequest(person Person, manager *Manager) error {
+	log.Println("[INFO]: loading discord config")
+
+	// Attempt to load the discord config
+	config, ok := manager.moduleConfigs["discord"].(DiscordConfig)
+	if !ok {
+		return fmt.Errorf("discord config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("discord session is nil")
 	}
 
+	// Generate an availability for the person
+	manager.edit.Lock()
+	manager.availability[person.Name] = manager.generateAvailability()
+	manager.tentative[person.Name] = manager.generateAvailability()
+	manager.edit.Unlock()
+
+	log.Println("[INFO]: generating availability dates")
+
+	// Generate all dates in the availability map
+	dates := manager.generateTimestamps()
+
 	log.Printf("[INFO]: opening discord channel to id '%v'\n", person.ID)
 
 	// Create a private channel to DM the user
@@ -128,43 +287,156 @@ func DiscordRequest(person Person, manager *Manager) error {
 	log.Println("[INFO]: sending discord header")
 
 	// Send the header message
-	_, err = config.Session.ChannelMessageSend(channel.ID, fmt.Sprintf(discordRequestHeader, manager.config.Title))
+	_, err = config.Session.ChannelMessageSend(channel.ID, manager.requestHeader(discordRequestHeader, manager.location(person), ""))
+	if err != nil {
+		return err
+	}
+
+	// Pre-select the dates the person's calendar is free on
+	defaults := manager.loadDefaults(person)
+	if defaults != nil {
+		if _, err = config.Session.ChannelMessageSend(channel.ID, manager.text(discordCalendarNote)); err != nil {
+			return err
+		}
+	}
+
+	log.Println("[INFO]: sending discord select menus")
+
+	// Send messages
+	for i := 0; i*discordMenuSize < len(dates); i++ {
+		entry := discordEntry{
+			Person:    person.Name,
+			Mode:      discordModeComponents,
+			Index:     i,
+			ChannelID: channel.ID,
+			Selection: discordSelection(defaults, nil, dates, i),
+			Manager:   manager,
+		}
+
+		// Send the select menus
+		m, err := config.Session.ChannelMessageSendComplex(channel.ID, &discordgo.MessageSend{
+			Content:    manager.text(discordComponentsBody),
+			Components: discordComponents(manager, &entry, dates),
+		})
+		if err != nil {
+			return err
+		}
+
+		// Add this message as a recorded entry
+		entry.MessageID = m.ID
+		discordEntries = append(discordEntries, &entry)
+
+		// If using SQL, add to SQL database
+		if manager.options.UseSQL {
+			log.Println("[INFO]: adding discord entry to SQL")
+			if err := manager.db.Save(&entry).Error; err != nil {
+				log.Printf("[ERR]: error saving discord entry to SQL (err: %v)\n", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Request an availability schedule using a shared discord guild channel
+func DiscordChannelRequest(person Person, manager *Manager) error {
+	log.Println("[INFO]: loading discord config")
+
+	// Attempt to load the discord config
+	config, ok := manager.moduleConfigs["discord"].(DiscordConfig)
+	if !ok {
+		return fmt.Errorf("discord config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("discord session is nil")
+	}
+
+	// Check if the channel is valid
+	channelID := manager.config.Discord.ChannelID
+	if channelID == "" {
+		return fmt.Errorf("discord channel ID is not set")
+	}
+
+	// The schedule is shared by everyone in the channel, so it is only sent once
+	for _, entry := range discordEntries {
+		if entry.Mode == discordModeChannel {
+			log.Printf("[INFO]: discord channel schedule already sent, skipping '%v'\n", person.Name)
+			return nil
+		}
+	}
+
+	// Mention every person that responds in the channel
+	mentions := []string{}
+	for _, p := range manager.config.Persons {
+		if p.RequestMethod == "discord_channel" {
+			mentions = append(mentions, fmt.Sprintf("<@%v>", p.ID))
+		}
+	}
+
+	log.Printf("[INFO]: sending discord header to channel '%v'\n", channelID)
+
+	// Send the header message
+	_, err := config.Session.ChannelMessageSend(channelID, manager.requestHeader(discordChannelRequestHeader, manager.loc, strings.Join(mentions, " ")))
 	if err != nil {
 		return err
 	}
 
+	return discordSendSchedule(config, manager, channelID, "", discordModeChannel, nil)
+}
+
+// Send the schedule messages to a discord channel and record them as entries. Dates that are busy in the given
+// defaults are marked as busy
+func discordSendSchedule(config DiscordConfig, manager *Manager, channelID string, name string, mode string, defaults map[string]bool) error {
+	log.Println("[INFO]: generating availability dates")
+
+	// Generate all dates in the availability map
+	dates := manager.generateTimestamps()
+
 	log.Println("[INFO]: sending discord messages")
 
 	// Send messages
-	for i := 0; i*7 < manager.config.Interval; i++ {
+	for i := 0; i*7 < len(dates); i++ {
+		data := manager.requestData(manager.loc, "")
+		data.Dates = []MessageDate{}
+
 		// Get a list of dates and the emoji - date paris for the message
 		emojiDates := ""
-		for j := 0; j < 7 && i*7+j < manager.config.Interval; j++ {
-			emojiDates += fmt.Sprintf("%v - %v\n", emojis[j], dates[i*7+j])
+		for j := 0; j < 7 && i*7+j < len(dates); j++ {
+			busy := defaults != nil && !defaults[dates[i*7+j]]
+			data.Dates = append(data.Dates, MessageDate{Date: manager.formatTimestamp(dates[i*7+j]), Emoji: emojis[j], Busy: busy})
+
+			emojiDates += fmt.Sprintf("%v - %v", emojis[j], manager.formatTimestamp(dates[i*7+j]))
+			if busy {
+				emojiDates += " " + manager.text("busy")
+			}
+			emojiDates += "\n"
 		}
 
-		// Send a DM
-		m, err := config.Session.ChannelMessageSend(channel.ID, fmt.Sprintf(discordRequestBody, emojiDates))
+		// Send the message
+		m, err := config.Session.ChannelMessageSend(channelID, manager.render(templateRequestBody, data, discordRequestBody, emojiDates))
 		if err != nil {
 			return err
 		}
 
-		// React to the DM with the emojis so the user can easily react
-		for j := 0; j < len(emojis) && i*7+j < manager.config.Interval; j++ {
-			if err = config.Session.MessageReactionAdd(channel.ID, m.ID, emojis[j]); err != nil {
+		// React to the message with the emojis so users can easily react
+		for j := 0; j < len(emojis) && i*7+j < len(dates); j++ {
+			if err = config.Session.MessageReactionAdd(channelID, m.ID, emojis[j]); err != nil {
 				return err
 			}
 		}
 		// Add a reaction for no date
-		if err = config.Session.MessageReactionAdd(channel.ID, m.ID, "❌"); err != nil {
+		if err = config.Session.MessageReactionAdd(channelID, m.ID, "❌"); err != nil {
 			return err
 		}
 
 		// Add this message as a recorded entry
 		entry := discordEntry{
-			Person:    person.Name,
+			Person:    name,
+			Mode:      mode,
 			Index:     i,
-			ChannelID: channel.ID,
+			ChannelID: channelID,
 			MessageID: m.ID,
 			Manager:   manager,
 		}
@@ -197,28 +469,13 @@ func DiscordGather(person Person, manager *Manager) error {
 		return fmt.Errorf("discord session is nil")
 	}
 
-	log.Println("[INFO]: generating availability dates")
-
-	// Generate all dates in the availability map
-	year, month, day := manager.ContactDay.Time.Date()
-	today := time.Date(year, month, day, 0, 0, 0, 0, manager.loc)
-
-	dates := []string{}
-	for day := manager.config.Offset; day < manager.config.Interval+manager.config.Offset; day++ {
-		timestamp := today.Add(time.Duration(DAY_DURATION * day)).Format(TIME_FORMAT)
-		dates = append(dates, timestamp)
-	}
-
-	// Generate an availability for the person
-	availability := manager.generateAvailability()
-
 	log.Printf("[INFO]: collecting discord entries for '%v'", person.Name)
 
 	// Filter for entries for this specific person
 	var entries []*discordEntry
 	for i := 0; i < len(discordEntries); i++ {
 		// On matching entry, add to local array and remove from global
-		if discordEntries[i].Person == person.Name {
+		if discordEntries[i].Mode == discordModeDirect && discordEntries[i].Person == person.Name {
 			entries = append(entries, discordEntries[i])
 
 			// If using SQL, remove from SQL database
@@ -233,60 +490,91 @@ func DiscordGather(person Person, manager *Manager) error {
 		}
 	}
 
-	// Sort entries based on index
-	for i := 1; i < len(entries); i++ {
-		cur := entries[i]
-		j := i - 1
+	// Read the person's reactions
+	availability, answered := discordReadReactions(config, manager, person, entries)
+	if answered {
+		manager.markResponded(person.Name)
+	}
 
-		for j >= 0 && entries[j].Index > cur.Index {
-			entries[j+1] = entries[j]
-			j--
-		}
-		entries[j+1] = cur
+	// Log the user's availability
+	for date, status := range availability {
+		log.Printf("[INFO]: user '%v' availability status on %v is %v\n", person.Name, date, status)
 	}
 
-	for i, entry := range entries {
-		log.Printf("[INFO]: determining reactions for '%v' with entry number '%v' and message id '%v'\n", entry.Person, entry.Index, entry.MessageID)
+	// Update the user's availability in the manager
+	manager.edit.Lock()
+	manager.availability[person.Name] = availability
+	manager.edit.Unlock()
+
+	// Answers changed with the '/align availability' command take precedence over reactions
+	discordCloseComponents(config, manager, person)
 
-		// Check if the user responded with an X
-		users, err := config.Session.MessageReactions(entry.ChannelID, entry.MessageID, "❌", 2, "", "")
-		if err != nil {
-			log.Printf("[ERR]: error getting message reactions from user '%v' (err: %v)\n", person.Name, err)
+	return nil
+}
+
+// Read the current response for availability using discord without closing the request
+func DiscordPeek(person Person, manager *Manager) (map[string]bool, bool, error) {
+	// Attempt to load the discord config
+	config, ok := manager.moduleConfigs["discord"].(DiscordConfig)
+	if !ok {
+		return nil, false, fmt.Errorf("discord config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return nil, false, fmt.Errorf("discord session is nil")
+	}
+
+	// Filter for entries for this specific person
+	var entries []*discordEntry
+	for _, entry := range discordEntries {
+		if entry.Person == person.Name && entry.Mode == discordModeComponents {
+			// Answers changed with the '/align availability' command take precedence over reactions
+			manager.edit.Lock()
+			defer manager.edit.Unlock()
+
+			return copyAvailability(manager.availability[person.Name]), true, nil
 		}
 
-		// If the user responded with an X, skip this entry (they're not available)
-		if len(users) == 2 {
-			continue
+		if entry.Person == person.Name && entry.Mode == discordModeDirect {
+			entries = append(entries, entry)
 		}
+	}
 
-		// Check for reactions to individual dates
-		for j := 0; j < len(emojis) && i*7+j < manager.config.Interval; j++ {
-			// Get message reactions for a given emoji
-			users, err := config.Session.MessageReactions(entry.ChannelID, entry.MessageID, emojis[j], 2, "", "")
-			if err != nil {
-				log.Printf("[ERR]: error getting message reactions from user '%v' (err: %v)\n", person.Name, err)
-			}
+	availability, answered := discordReadReactions(config, manager, person, entries)
+	return availability, answered, nil
+}
 
-			// Set availability based on the reaction count
-			availability[dates[i*7+j]] = len(users) == 2
-		}
+// Read a response for availability from discord select menus
+func DiscordComponentsGather(person Person, manager *Manager) error {
+	log.Println("[INFO]: loading discord config")
+
+	// Attempt to load the discord config
+	config, ok := manager.moduleConfigs["discord"].(DiscordConfig)
+	if !ok {
+		return fmt.Errorf("discord config has not been initialized")
 	}
 
-	// Log the user's availability
-	for date, status := range availability {
-		log.Printf("[INFO]: user '%v' availability status on %v is %v\n", person.Name, date, status)
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("discord session is nil")
 	}
 
-	// Update the user's availability in the manager
+	// Apply and close the person's select menus
+	discordCloseComponents(config, manager, person)
+
+	// Log the user's availability
 	manager.edit.Lock()
-	manager.availability[person.Name] = availability
+	for date, status := range manager.availability[person.Name] {
+		log.Printf("[INFO]: user '%v' availability status on %v is %v (maybe %v)\n", person.Name, date, status, manager.tentative[person.Name][date])
+	}
 	manager.edit.Unlock()
 
 	return nil
 }
 
-// Send a user a response summary on discord
-func DiscordResponse(person Person, manager *Manager, days []day, unknowns []string, available int) error {
+// Read a response for availability from a shared discord guild channel
+func DiscordChannelGather(person Person, manager *Manager) error {
 	log.Println("[INFO]: loading discord config")
 
 	// Attempt to load the discord config
@@ -300,48 +588,1097 @@ func DiscordResponse(person Person, manager *Manager, days []day, unknowns []str
 		return fmt.Errorf("discord session is nil")
 	}
 
-	log.Println("[INFO]: building response string")
+	log.Println("[INFO]: collecting discord channel entries")
 
-	// Concatenate days to a single string
-	dayString := ""
-	for _, day := range days {
-		dayString += fmt.Sprintf("- %v (%v)\n", day.Timestamp, strings.Join(day.AvailablePersons, ", "))
+	// Filter for the shared channel entries. These are removed once the response is sent, since
+	// every person in the channel reads from them
+	var entries []*discordEntry
+	for _, entry := range discordEntries {
+		if entry.Mode == discordModeChannel {
+			entries = append(entries, entry)
+		}
 	}
 
-	// Concatenate unknowns into a single string
-	unknownsString := ""
-	for _, person := range unknowns {
-		unknownsString += fmt.Sprintf("- %v\n", person)
+	// Read the person's reactions
+	availability, answered := discordReadReactions(config, manager, person, entries)
+	if answered {
+		manager.markResponded(person.Name)
 	}
 
-	unknownPrefix := ""
-	if len(unknowns) > 0 {
-		unknownPrefix = "\nNo responses from:\n"
+	// Log the user's availability
+	for date, status := range availability {
+		log.Printf("[INFO]: user '%v' availability status on %v is %v\n", person.Name, date, status)
 	}
 
-	// Create a private channel to DM the user
-	channel, err := config.Session.UserChannelCreate(person.ID)
-	if err != nil {
-		return err
+	// Update the user's availability in the manager
+	manager.edit.Lock()
+	manager.availability[person.Name] = availability
+	manager.edit.Unlock()
+
+	// Answers changed with the '/align availability' command take precedence over reactions
+	discordCloseComponents(config, manager, person)
+
+	return nil
+}
+
+// Read the current response for availability from a shared discord guild channel without closing the request
+func DiscordChannelPeek(person Person, manager *Manager) (map[string]bool, bool, error) {
+	// Attempt to load the discord config
+	config, ok := manager.moduleConfigs["discord"].(DiscordConfig)
+	if !ok {
+		return nil, false, fmt.Errorf("discord config has not been initialized")
 	}
 
-	// Format the message to be sent
-	str := fmt.Sprintf(discordResponseBody,
-		manager.config.Title,
-		available,
-		len(manager.config.Persons),
-		dayString,
-		unknownPrefix,
-		unknownsString,
-	)
+	// Check if the session is valid
+	if config.Session == nil {
+		return nil, false, fmt.Errorf("discord session is nil")
+	}
 
-	log.Printf("[INFO]: sending response message\n%v\n", str)
+	// Filter for the shared channel entries
+	var entries []*discordEntry
+	for _, entry := range discordEntries {
+		if entry.Person == person.Name && entry.Mode == discordModeComponents {
+			// Answers changed with the '/align availability' command take precedence over reactions
+			manager.edit.Lock()
+			defer manager.edit.Unlock()
 
-	// Send a message to the user
-	_, err = config.Session.ChannelMessageSend(channel.ID, str)
-	if err != nil {
-		return err
+			return copyAvailability(manager.availability[person.Name]), true, nil
+		}
+
+		if entry.Mode == discordModeChannel {
+			entries = append(entries, entry)
+		}
 	}
 
-	return nil
+	availability, answered := discordReadReactions(config, manager, person, entries)
+	return availability, answered, nil
+}
+
+// Send a user a response summary on discord
+func DiscordResponse(person Person, manager *Manager, days []day, unknowns []string, available int) error {
+	log.Println("[INFO]: loading discord config")
+
+	// Attempt to load the discord config
+	config, ok := manager.moduleConfigs["discord"].(DiscordConfig)
+	if !ok {
+		return fmt.Errorf("discord config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("discord session is nil")
+	}
+
+	// Create a private channel to DM the user
+	channel, err := config.Session.UserChannelCreate(person.ID)
+	if err != nil {
+		return err
+	}
+
+	// Format the message to be sent
+	embed := discordFormatResponse(manager, days, unknowns, available)
+
+	log.Println("[INFO]: sending response message")
+
+	// Send a message to the user
+	return discordSendResponse(config, manager, channel.ID, embed, days)
+}
+
+// Send a response summary to a shared discord guild channel
+func DiscordChannelResponse(person Person, manager *Manager, days []day, unknowns []string, available int) error {
+	log.Println("[INFO]: loading discord config")
+
+	// Attempt to load the discord config
+	config, ok := manager.moduleConfigs["discord"].(DiscordConfig)
+	if !ok {
+		return fmt.Errorf("discord config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("discord session is nil")
+	}
+
+	// Check if the channel is valid
+	channelID := manager.config.Discord.ChannelID
+	if channelID == "" {
+		return fmt.Errorf("discord channel ID is not set")
+	}
+
+	// Collect the shared channel entries and remove them, so the response is only sent once
+	var entries []*discordEntry
+	for i := 0; i < len(discordEntries); i++ {
+		if discordEntries[i].Mode == discordModeChannel {
+			entries = append(entries, discordEntries[i])
+
+			// If using SQL, remove from SQL database
+			if manager.options.UseSQL {
+				if err := manager.db.Delete(&discordEntries[i]).Error; err != nil {
+					log.Printf("[ERR]: error deleting discord entry from SQL (err: %v)\n", err)
+				}
+			}
+
+			discordEntries = append(discordEntries[:i], discordEntries[i+1:]...)
+			i--
+		}
+	}
+
+	if len(entries) == 0 {
+		log.Printf("[INFO]: discord channel response already sent, skipping '%v'\n", person.Name)
+		return nil
+	}
+
+	// Sort entries based on index
+	sortDiscordEntries(entries)
+
+	// If requested, start a thread off of the first schedule message and respond there
+	if manager.config.Discord.Thread {
+		log.Println("[INFO]: starting discord response thread")
+
+		thread, err := config.Session.MessageThreadStart(channelID, entries[0].MessageID, manager.config.Title, 1440)
+		if err != nil {
+			log.Printf("[ERR]: error starting discord thread, responding in channel instead (err: %v)\n", err)
+		} else {
+			channelID = thread.ID
+		}
+	}
+
+	// Format the message to be sent
+	embed := discordFormatResponse(manager, days, unknowns, available)
+
+	log.Println("[INFO]: sending channel response message")
+
+	// Send a message to the channel
+	return discordSendResponse(config, manager, channelID, embed, days)
+}
+
+// Tell a person which day was decided on using discord, asking whether they are going if confirmations are enabled
+func DiscordAnnounce(person Person, manager *Manager, event Event) error {
+	log.Println("[INFO]: loading discord config")
+
+	// Attempt to load the discord config
+	config, ok := manager.moduleConfigs["discord"].(DiscordConfig)
+	if !ok {
+		return fmt.Errorf("discord config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("discord session is nil")
+	}
+
+	log.Printf("[INFO]: opening discord channel to id '%v'\n", person.ID)
+
+	// Create a private channel to DM the user
+	channel, err := config.Session.UserChannelCreate(person.ID)
+	if err != nil {
+		return err
+	}
+
+	return discordSendAnnouncement(config, manager, channel.ID, "", event, manager.eventWhen(event, person))
+}
+
+// Tell a shared discord guild channel which day was decided on, asking whether persons are going if confirmations
+// are enabled
+func DiscordChannelAnnounce(person Person, manager *Manager, event Event) error {
+	log.Println("[INFO]: loading discord config")
+
+	// Attempt to load the discord config
+	config, ok := manager.moduleConfigs["discord"].(DiscordConfig)
+	if !ok {
+		return fmt.Errorf("discord config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("discord session is nil")
+	}
+
+	// Check if the channel is valid
+	channelID := manager.config.Discord.ChannelID
+	if channelID == "" {
+		return fmt.Errorf("discord channel ID is not set")
+	}
+
+	// Anyone in the channel can press the buttons, so the person is mentioned to know the question is for them
+	return discordSendAnnouncement(config, manager, channelID, fmt.Sprintf("<@%v> ", person.ID), event, manager.eventWhen(event, person))
+}
+
+// Send the decided event to a discord channel, with going and not going buttons if confirmations are enabled
+func discordSendAnnouncement(config DiscordConfig, manager *Manager, channelID string, prefix string, event Event, when string) error {
+	log.Printf("[INFO]: sending discord announcement to channel '%v'\n", channelID)
+
+	message := &discordgo.MessageSend{
+		Content: manager.text(discordAnnouncementBody, prefix, event.Title, when),
+	}
+
+	if manager.config.Confirm {
+		message.Content += manager.text(discordRSVPBody)
+		message.Components = []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    manager.text("going"),
+						Style:    discordgo.SuccessButton,
+						CustomID: "align_rsvp:yes",
+					},
+					discordgo.Button{
+						Label:    manager.text("not_going"),
+						Style:    discordgo.DangerButton,
+						CustomID: "align_rsvp:no",
+					},
+				},
+			},
+		}
+	}
+
+	_, err := config.Session.ChannelMessageSendComplex(channelID, message)
+	return err
+}
+
+// Remind a person of the decided event using discord
+func DiscordRemind(person Person, manager *Manager, event Event) error {
+	log.Println("[INFO]: loading discord config")
+
+	// Attempt to load the discord config
+	config, ok := manager.moduleConfigs["discord"].(DiscordConfig)
+	if !ok {
+		return fmt.Errorf("discord config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("discord session is nil")
+	}
+
+	log.Printf("[INFO]: opening discord channel to id '%v'\n", person.ID)
+
+	// Create a private channel to DM the user
+	channel, err := config.Session.UserChannelCreate(person.ID)
+	if err != nil {
+		return err
+	}
+
+	when := manager.eventWhen(event, person)
+	_, err = config.Session.ChannelMessageSend(channel.ID, manager.render(templateReminder, manager.eventData(event, when, ""), discordReminderBody, "", event.Title, when))
+	return err
+}
+
+// Remind a person of the decided event in a shared discord guild channel
+func DiscordChannelRemind(person Person, manager *Manager, event Event) error {
+	log.Println("[INFO]: loading discord config")
+
+	// Attempt to load the discord config
+	config, ok := manager.moduleConfigs["discord"].(DiscordConfig)
+	if !ok {
+		return fmt.Errorf("discord config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("discord session is nil")
+	}
+
+	// Check if the channel is valid
+	channelID := manager.config.Discord.ChannelID
+	if channelID == "" {
+		return fmt.Errorf("discord channel ID is not set")
+	}
+
+	when := manager.eventWhen(event, person)
+	mention := fmt.Sprintf("<@%v>", person.ID)
+	_, err := config.Session.ChannelMessageSend(channelID, manager.render(templateReminder, manager.eventData(event, when, mention), discordReminderBody, mention+" ", event.Title, when))
+	return err
+}
+
+// Send an organizer the results and a menu to pick the final day with, using discord
+func DiscordApprove(person Person, manager *Manager, days []day, unknowns []string, available int) error {
+	log.Println("[INFO]: loading discord config")
+
+	// Attempt to load the discord config
+	config, ok := manager.moduleConfigs["discord"].(DiscordConfig)
+	if !ok {
+		return fmt.Errorf("discord config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("discord session is nil")
+	}
+
+	log.Printf("[INFO]: opening discord channel to id '%v'\n", person.ID)
+
+	// Organizers always decide in private, even if the group uses a shared channel
+	channel, err := config.Session.UserChannelCreate(person.ID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := config.Session.ChannelMessageSendComplex(channel.ID, discordResultMessage(manager, discordFormatResponse(manager, days, unknowns, available))); err != nil {
+		return err
+	}
+
+	// The best days are listed first, and organizers can override them with any other date. Menus
+	// hold at most 25 options, so later days are left out when there are more
+	best := map[string]bool{}
+	options := []discordgo.SelectMenuOption{}
+	for _, d := range days {
+		if len(options) == discordMenuSize {
+			break
+		}
+
+		best[d.Timestamp] = true
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       manager.formatTimestamp(d.Timestamp),
+			Value:       d.Timestamp,
+			Description: manager.text("discord_available", len(d.AvailablePersons)),
+			Emoji:       &discordgo.ComponentEmoji{Name: "⭐"},
+		})
+	}
+	for _, date := range manager.generateTimestamps() {
+		if !best[date] && len(options) < discordMenuSize {
+			options = append(options, discordgo.SelectMenuOption{Label: manager.formatTimestamp(date), Value: date})
+		}
+	}
+
+	log.Println("[INFO]: sending discord approval menu")
+
+	_, err = config.Session.ChannelMessageSendComplex(channel.ID, &discordgo.MessageSend{
+		Content: manager.text(discordApprovalBody, manager.config.Title),
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.SelectMenu{
+						CustomID:    "align_pick:date",
+						Placeholder: manager.text("discord_final_day"),
+						Options:     options,
+					},
+				},
+			},
+		},
+	})
+
+	return err
+}
+
+// Create a message for a response summary, showing the heatmap of everyone's availability in the embed
+func discordResultMessage(manager *Manager, embed *discordgo.MessageEmbed) *discordgo.MessageSend {
+	message := &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed},
+	}
+
+	if heatmap, ok := manager.Heatmap(); ok {
+		message.Files = append(message.Files, &discordgo.File{
+			Name:        "heatmap.png",
+			ContentType: "image/png",
+			Reader:      bytes.NewReader(heatmap),
+		})
+		embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://heatmap.png"}
+	}
+
+	return message
+}
+
+// Send a response summary to a discord channel, attaching a calendar file for the best day if requested
+func discordSendResponse(config DiscordConfig, manager *Manager, channelID string, embed *discordgo.MessageEmbed, days []day) error {
+	message := discordResultMessage(manager, embed)
+
+	if manager.config.AttachCalendar && len(days) > 0 {
+		log.Println("[INFO]: attaching calendar file")
+
+		message.Files = append(message.Files, &discordgo.File{
+			Name:        "align.ics",
+			ContentType: "text/calendar",
+			Reader:      bytes.NewReader(manager.newEvent(days[0]).ICS()),
+		})
+	}
+
+	_, err := config.Session.ChannelMessageSendComplex(channelID, message)
+	return err
+}
+
+// Format a response summary for discord as an embed, with a field for every best day
+func discordFormatResponse(manager *Manager, days []day, unknowns []string, available int) *discordgo.MessageEmbed {
+	log.Println("[INFO]: building response embed")
+
+	embed := &discordgo.MessageEmbed{
+		Title: manager.text(discordResultTitle, manager.config.Title),
+		Color: discordResultColor,
+	}
+
+	// No day works when nobody is available on any date. A group's own template replaces the whole summary
+	data := manager.resultData(days, unknowns, available)
+	if len(days) == 0 {
+		embed.Color = discordNoResultColor
+		embed.Description = manager.render(templateNoResult, data, "nobody_available")
+		if _, ok := manager.templates[templateNoResult]; ok {
+			return embed
+		}
+	} else {
+		embed.Description = manager.render(templateResult, data, discordResultAvailable, available, len(manager.config.Persons))
+		if _, ok := manager.templates[templateResult]; ok {
+			return embed
+		}
+	}
+
+	// Add a field for every day, leaving room for the unknowns and excluded dates
+	for _, day := range days {
+		if len(embed.Fields) == discordEmbedFields-2 {
+			break
+		}
+
+		value := discordEscape(strings.Join(day.AvailablePersons, ", "))
+		if len(day.TentativePersons) > 0 {
+			value += "\n" + manager.text("maybe", discordEscape(strings.Join(day.TentativePersons, ", ")))
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  manager.formatTimestamp(day.Timestamp),
+			Value: discordFieldValue(value),
+		})
+	}
+
+	if len(unknowns) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  discordFieldName(manager.text("no_responses")),
+			Value: discordFieldValue(discordEscape(strings.Join(unknowns, "\n"))),
+		})
+	}
+
+	// Explain why excluded dates weren't asked about
+	if excluded := manager.excludedDates(); len(excluded) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  discordFieldName(manager.text("not_asked")),
+			Value: discordFieldValue(discordEscape(strings.Join(excluded, "\n"))),
+		})
+	}
+
+	return embed
+}
+
+// Turn a label such as 'No responses from:' into the name of an embed field
+func discordFieldName(label string) string {
+	return strings.TrimSpace(strings.TrimSuffix(label, ":"))
+}
+
+// Shorten the value of an embed field to the length discord allows, ending it with an ellipsis if it is cut off
+func discordFieldValue(value string) string {
+	runes := []rune(value)
+	if len(runes) <= discordFieldLength {
+		return value
+	}
+
+	// Don't leave half of an escaped character behind
+	cut := string(runes[:discordFieldLength-1])
+	if trimmed := strings.TrimRight(cut, `\`); (len(cut)-len(trimmed))%2 == 1 {
+		cut = cut[:len(cut)-1]
+	}
+
+	return cut + "…"
+}
+
+// Escape text so discord shows it as it is written instead of formatting it with markdown
+func discordEscape(text string) string {
+	return discordEscaper.Replace(text)
+}
+
+// Update a person's component selection from a select menu interaction
+func discordHandleComponent(manager *Manager, s DiscordSession, i *discordgo.InteractionCreate) {
+	data := i.MessageComponentData()
+
+	// Custom IDs are formatted as 'align_yes:<index>', 'align_maybe:<index>', 'align_rsvp:<yes|no>' or 'align_pick:date'
+	kind, index, ok := strings.Cut(data.CustomID, ":")
+	if !ok || (kind != "align_yes" && kind != "align_maybe" && kind != "align_rsvp" && kind != "align_pick") {
+		return
+	}
+
+	// Find the person who interacted with the menu
+	user := i.User
+	if i.Member != nil {
+		user = i.Member.User
+	}
+
+	person, ok := manager.discordPerson(user.ID)
+	if !ok {
+		discordRespondEphemeral(s, i, manager.text("not_in_schedule"))
+		return
+	}
+
+	// Lock in the day an organizer picked
+	if kind == "align_pick" {
+		if !person.Organizer {
+			discordRespondEphemeral(s, i, manager.text("only_organizers"))
+			return
+		}
+
+		if len(data.Values) == 0 || !manager.scheduled(data.Values[0]) {
+			discordRespondEphemeral(s, i, manager.text("schedule_closed"))
+			return
+		}
+
+		if !manager.awaitingPick() {
+			discordRespondEphemeral(s, i, manager.text("already_picked"))
+			return
+		}
+
+		// Respond before deciding, as announcing the decision can take longer than discord waits for a response
+		discordRespondEphemeral(s, i, manager.text("decided_announcing", manager.formatTimestamp(data.Values[0])))
+
+		if err := manager.decide(data.Values[0], true); err != nil {
+			log.Printf("[ERR]: error deciding on '%v' (err: %v)\n", data.Values[0], err)
+		}
+		return
+	}
+
+	// Record whether the person is going to the decided event
+	if kind == "align_rsvp" {
+		if err := manager.confirm(person.Name, index == "yes"); err != nil {
+			discordRespondEphemeral(s, i, manager.text("no_upcoming_event"))
+			return
+		}
+
+		if index == "yes" {
+			discordRespondEphemeral(s, i, manager.text("see_you_there"))
+		} else {
+			discordRespondEphemeral(s, i, manager.text("thanks"))
+		}
+		return
+	}
+
+	// Find the entry the menu belongs to
+	var entry *discordEntry
+	for _, e := range discordEntries {
+		if e.Mode == discordModeComponents && e.Person == person.Name && fmt.Sprint(e.Index) == index {
+			entry = e
+			break
+		}
+	}
+
+	// The menu can't be used if the schedule has changed since it was sent, such as when a new schedule started
+	dates := manager.generateTimestamps()
+	if entry == nil || entry.Index*discordMenuSize+len(entry.Selection) > len(dates) {
+		discordRespondEphemeral(s, i, manager.text("schedule_closed"))
+		return
+	}
+
+	// Set the state of every date in the menu
+	state := byte(discordStateYes)
+	if kind == "align_maybe" {
+		state = discordStateMaybe
+	}
+
+	chosen := map[string]bool{}
+	for _, value := range data.Values {
+		chosen[value] = true
+	}
+
+	selection := []byte(entry.Selection)
+	for j := range selection {
+		if chosen[fmt.Sprint(j)] {
+			selection[j] = state
+		} else if selection[j] == state {
+			selection[j] = discordStateNo
+		}
+	}
+	entry.Selection = string(selection)
+
+	manager.discordApplySelection(entry)
+	manager.markResponded(person.Name)
+
+	// If using SQL, save the selection
+	if manager.options.UseSQL {
+		if err := manager.db.Save(entry).Error; err != nil {
+			log.Printf("[ERR]: error saving discord entry to SQL (err: %v)\n", err)
+		}
+	}
+
+	// Confirm the person's current selection
+	summary := manager.text("current_selection") + "\n"
+	for j, state := range selection {
+		summary += fmt.Sprintf("%v %v\n", discordStateIcon(state), manager.formatTimestamp(dates[entry.Index*discordMenuSize+j]))
+	}
+
+	discordRespondEphemeral(s, i, summary)
+}
+
+// Handle the '/align' slash command
+func discordHandleCommand(manager *Manager, s DiscordSession, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	if data.Name != discordCommand.Name || len(data.Options) == 0 {
+		return
+	}
+
+	// Find the person who used the command
+	user := i.User
+	if i.Member != nil {
+		user = i.Member.User
+	}
+
+	person, ok := manager.discordPerson(user.ID)
+	if !ok {
+		discordRespondEphemeral(s, i, manager.text("not_in_schedule"))
+		return
+	}
+
+	log.Printf("[INFO]: '%v' used discord command '%v'\n", person.Name, data.Options[0].Name)
+
+	// Results can be read at any time, everything else needs a schedule to be running
+	if data.Options[0].Name == "results" {
+		manager.edit.Lock()
+		r := manager.LastResult
+		manager.edit.Unlock()
+
+		if r == nil {
+			discordRespondEphemeral(s, i, manager.text("no_results"))
+			return
+		}
+
+		discordRespondEphemeralEmbed(s, i, discordFormatResponse(manager, r.Days, r.Unknowns, r.Available))
+		return
+	}
+
+	if data.Options[0].Name == "event" {
+		discordRespondEphemeral(s, i, discordFormatEvent(manager))
+		return
+	}
+
+	if !manager.ContactDay.Valid {
+		discordRespondEphemeral(s, i, manager.text("no_schedule"))
+		return
+	}
+
+	switch data.Options[0].Name {
+	case "status":
+		// Reading everyone's answers can take longer than discord waits for a response, so the status is sent later
+		if err := discordDeferEphemeral(s, i); err != nil {
+			log.Printf("[ERR]: error deferring discord interaction (err: %v)\n", err)
+			return
+		}
+
+		discordFollowupEphemeral(s, i, discordFormatStatus(manager, person))
+
+	case "optout":
+		manager.skip(person.Name)
+		discordRespondEphemeral(s, i, manager.text("discord_opted_out", manager.config.Title))
+
+	case "availability":
+		discordSendAvailabilityMenus(manager, s, i, person)
+	}
+}
+
+// Respond to an interaction with select menus to change a person's availability
+func discordSendAvailabilityMenus(manager *Manager, s DiscordSession, i *discordgo.InteractionCreate, person Person) {
+	// Changing your availability opts you back in
+	manager.edit.Lock()
+	delete(manager.skipped, person.Name)
+	manager.edit.Unlock()
+
+	// Start the menus from the person's current answer
+	availability, tentative, _ := manager.current(person)
+	dates := manager.generateTimestamps()
+
+	messages := [][]discordgo.MessageComponent{}
+	for index := 0; index*discordMenuSize < len(dates); index++ {
+		// Reuse the person's existing menus so their selection carries over
+		var entry *discordEntry
+		for _, e := range discordEntries {
+			if e.Mode == discordModeComponents && e.Person == person.Name && e.Index == index {
+				entry = e
+				break
+			}
+		}
+
+		if entry == nil {
+			entry = &discordEntry{
+				Person:    person.Name,
+				Mode:      discordModeComponents,
+				Index:     index,
+				ChannelID: i.ChannelID,
+				Selection: discordSelection(availability, tentative, dates, index),
+				Manager:   manager,
+			}
+			discordEntries = append(discordEntries, entry)
+
+			// If using SQL, add to SQL database
+			if manager.options.UseSQL {
+				log.Println("[INFO]: adding discord entry to SQL")
+				if err := manager.db.Save(entry).Error; err != nil {
+					log.Printf("[ERR]: error saving discord entry to SQL (err: %v)\n", err)
+				}
+			}
+		}
+
+		// A message can only hold five rows, so two sets of menus are sent per message
+		components := discordComponents(manager, entry, dates)
+		if index%2 == 0 {
+			messages = append(messages, components)
+		} else {
+			messages[len(messages)-1] = append(messages[len(messages)-1], components...)
+		}
+	}
+
+	if len(messages) == 0 {
+		discordRespondEphemeral(s, i, manager.text("discord_no_dates"))
+		return
+	}
+
+	// Respond with the first menus, and follow up with the rest
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:    manager.text(discordComponentsBody),
+			Components: messages[0],
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		log.Printf("[ERR]: error responding to discord interaction (err: %v)\n", err)
+		return
+	}
+
+	for _, components := range messages[1:] {
+		if _, err := s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral,
+		}); err != nil {
+			log.Printf("[ERR]: error following up discord interaction (err: %v)\n", err)
+		}
+	}
+}
+
+// Respond to an interaction with a message only the interacting user can see
+func discordRespondEphemeral(s DiscordSession, i *discordgo.InteractionCreate, content string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		log.Printf("[ERR]: error responding to discord interaction (err: %v)\n", err)
+	}
+}
+
+// Acknowledge an interaction whose answer takes a while, showing the interacting user that it is on its way. The
+// answer is sent with discordFollowupEphemeral
+func discordDeferEphemeral(s DiscordSession, i *discordgo.InteractionCreate) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// Send the answer to a deferred interaction so only the interacting user can see it
+func discordFollowupEphemeral(s DiscordSession, i *discordgo.InteractionCreate, content string) {
+	_, err := s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+		Content: content,
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+	if err != nil {
+		log.Printf("[ERR]: error following up on discord interaction (err: %v)\n", err)
+	}
+}
+
+// Respond to an interaction with an embed only the interacting user can see
+func discordRespondEphemeralEmbed(s DiscordSession, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		log.Printf("[ERR]: error responding to discord interaction (err: %v)\n", err)
+	}
+}
+
+// Build the select menus for a components entry
+func discordComponents(manager *Manager, entry *discordEntry, dates []string) []discordgo.MessageComponent {
+	yes := []discordgo.SelectMenuOption{}
+	maybe := []discordgo.SelectMenuOption{}
+	for j, state := range []byte(entry.Selection) {
+		// Dates that are no longer being asked about aren't offered
+		if entry.Index*discordMenuSize+j >= len(dates) {
+			break
+		}
+
+		date := manager.formatTimestamp(dates[entry.Index*discordMenuSize+j])
+
+		yes = append(yes, discordgo.SelectMenuOption{Label: date, Value: fmt.Sprint(j), Default: state == discordStateYes})
+		maybe = append(maybe, discordgo.SelectMenuOption{Label: date, Value: fmt.Sprint(j), Default: state == discordStateMaybe})
+	}
+
+	minValues := 0
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID:    fmt.Sprintf("align_yes:%v", entry.Index),
+				Placeholder: manager.text("discord_free_placeholder"),
+				MinValues:   &minValues,
+				MaxValues:   len(yes),
+				Options:     yes,
+			},
+		}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID:    fmt.Sprintf("align_maybe:%v", entry.Index),
+				Placeholder: manager.text("discord_maybe_placeholder"),
+				MinValues:   &minValues,
+				MaxValues:   len(maybe),
+				Options:     maybe,
+			},
+		}},
+	}
+}
+
+// Build the selection for a set of select menus from an availability and tentative availability
+func discordSelection(availability map[string]bool, tentative map[string]bool, dates []string, index int) string {
+	selection := []byte{}
+	for j := index * discordMenuSize; j < len(dates) && j < (index+1)*discordMenuSize; j++ {
+		switch {
+		case availability[dates[j]]:
+			selection = append(selection, discordStateYes)
+		case tentative[dates[j]]:
+			selection = append(selection, discordStateMaybe)
+		default:
+			selection = append(selection, discordStateNo)
+		}
+	}
+
+	return string(selection)
+}
+
+// Apply a components entry's selection to the person's availability
+func (m *Manager) discordApplySelection(entry *discordEntry) {
+	dates := m.generateTimestamps()
+
+	m.edit.Lock()
+	defer m.edit.Unlock()
+
+	if _, ok := m.availability[entry.Person]; !ok {
+		m.availability[entry.Person] = m.generateAvailability()
+	}
+	if _, ok := m.tentative[entry.Person]; !ok {
+		m.tentative[entry.Person] = m.generateAvailability()
+	}
+
+	for j, state := range []byte(entry.Selection) {
+		// Ignore selections for dates that are no longer being asked about
+		if entry.Index*discordMenuSize+j >= len(dates) {
+			break
+		}
+
+		date := dates[entry.Index*discordMenuSize+j]
+		m.availability[entry.Person][date] = state == discordStateYes
+		m.tentative[entry.Person][date] = state == discordStateMaybe
+	}
+}
+
+// Find the discord person with the given user ID
+func (m *Manager) discordPerson(userID string) (Person, bool) {
+	for _, p := range m.config.Persons {
+		if strings.HasPrefix(p.RequestMethod, "discord") && p.ID == userID {
+			return p, true
+		}
+	}
+
+	return Person{}, false
+}
+
+// Get the icon shown for a component state
+func discordStateIcon(state byte) string {
+	switch state {
+	case discordStateYes:
+		return "✅"
+	case discordStateMaybe:
+		return "❔"
+	default:
+		return "⬜"
+	}
+}
+
+// Apply the selections of a person's select menus to their availability and close the menus
+func discordCloseComponents(config DiscordConfig, manager *Manager, person Person) {
+	log.Printf("[INFO]: collecting discord entries for '%v'", person.Name)
+
+	// Filter for entries for this specific person
+	var entries []*discordEntry
+	for i := 0; i < len(discordEntries); i++ {
+		// On matching entry, add to local array and remove from global
+		if discordEntries[i].Mode == discordModeComponents && discordEntries[i].Person == person.Name {
+			entries = append(entries, discordEntries[i])
+
+			// If using SQL, remove from SQL database
+			if manager.options.UseSQL {
+				if err := manager.db.Delete(&discordEntries[i]).Error; err != nil {
+					log.Printf("[ERR]: error deleting discord entry from SQL (err: %v)\n", err)
+				}
+			}
+
+			discordEntries = append(discordEntries[:i], discordEntries[i+1:]...)
+			i--
+		}
+	}
+
+	log.Printf("[INFO]: closing discord select menus for '%v'\n", person.Name)
+
+	for _, entry := range entries {
+		// Apply the final selection of the select menus
+		manager.discordApplySelection(entry)
+
+		// Menus sent in response to a command can't be edited later
+		if entry.MessageID == "" {
+			continue
+		}
+
+		// Remove the select menus so the selection can't be changed anymore
+		components := []discordgo.MessageComponent{}
+		if _, err := config.Session.ChannelMessageEditComplex(&discordgo.MessageEdit{
+			ID:         entry.MessageID,
+			Channel:    entry.ChannelID,
+			Components: &components,
+		}); err != nil {
+			log.Printf("[ERR]: error closing discord select menus (err: %v)\n", err)
+		}
+	}
+}
+
+// Read a person's reactions to schedule entries. Returns the person's availability and whether they reacted at all
+func discordReadReactions(config DiscordConfig, manager *Manager, person Person, entries []*discordEntry) (map[string]bool, bool) {
+	// Generate all dates in the availability map
+	dates := manager.generateTimestamps()
+
+	// Generate an availability for the person
+	availability := manager.generateAvailability()
+	answered := false
+
+	// Sort entries based on index
+	sortDiscordEntries(entries)
+
+	for _, entry := range entries {
+		log.Printf("[INFO]: determining reactions for '%v' with entry number '%v' and message id '%v'\n", person.Name, entry.Index, entry.MessageID)
+
+		// If the user responded with an X, skip this entry (they're not available)
+		reacted, err := discordReacted(config, entry, "❌", person.ID)
+		if err != nil {
+			log.Printf("[ERR]: error getting message reactions from user '%v' (err: %v)\n", person.Name, err)
+		}
+		if reacted {
+			answered = true
+			continue
+		}
+
+		// Check for reactions to individual dates
+		for j := 0; j < len(emojis) && entry.Index*7+j < len(dates); j++ {
+			reacted, err := discordReacted(config, entry, emojis[j], person.ID)
+			if err != nil {
+				log.Printf("[ERR]: error getting message reactions from user '%v' (err: %v)\n", person.Name, err)
+			}
+
+			// Set availability based on whether the user reacted
+			availability[dates[entry.Index*7+j]] = reacted
+			answered = answered || reacted
+		}
+	}
+
+	return availability, answered
+}
+
+// Format a person's current answer and who is still pending for discord
+func discordFormatStatus(manager *Manager, person Person) string {
+	availability, tentative, answered := manager.current(person)
+
+	manager.edit.Lock()
+	skipped := manager.skipped[person.Name]
+	manager.edit.Unlock()
+
+	// List the person's answer for every date
+	dateString := ""
+	switch {
+	case skipped:
+		dateString = manager.text("opted_out") + "\n"
+	case !answered:
+		dateString = manager.text("not_answered") + "\n"
+	default:
+		for _, date := range manager.generateTimestamps() {
+			state := byte(discordStateNo)
+			if availability[date] {
+				state = discordStateYes
+			} else if tentative[date] {
+				state = discordStateMaybe
+			}
+
+			dateString += fmt.Sprintf("%v %v\n", discordStateIcon(state), manager.formatTimestamp(date))
+		}
+	}
+
+	// List the persons who haven't answered yet
+	pendingString := manager.text("everyone_answered")
+	if pending := manager.pending(); len(pending) > 0 {
+		pendingString = manager.text("still_waiting", strings.Join(pending, ", "))
+	}
+
+	return manager.text(discordStatusBody, manager.config.Title, dateString, pendingString)
+}
+
+// Format who is going to the decided event for discord
+func discordFormatEvent(manager *Manager) string {
+	manager.edit.Lock()
+	event := manager.Decision
+	manager.edit.Unlock()
+
+	if event == nil {
+		return manager.text("no_event")
+	}
+
+	going, notGoing, pending := manager.confirmations()
+
+	return manager.text(discordEventBody,
+		event.Title,
+		manager.FormatDate(event.Date),
+		discordEscape(manager.listNames(going)),
+		discordEscape(manager.listNames(notGoing)),
+		discordEscape(manager.listNames(pending)),
+	)
+}
+
+// Check whether a user reacted to a discord entry with the given emoji
+func discordReacted(config DiscordConfig, entry *discordEntry, emoji string, userID string) (bool, error) {
+	afterID := ""
+	for {
+		// Discord returns at most 100 users per request, so page through the reactions
+		users, err := config.Session.MessageReactions(entry.ChannelID, entry.MessageID, emoji, 100, "", afterID)
+		if err != nil {
+			return false, err
+		}
+
+		for _, user := range users {
+			if user.ID == userID {
+				return true, nil
+			}
+		}
+
+		if len(users) < 100 {
+			return false, nil
+		}
+		afterID = users[len(users)-1].ID
+	}
+}
+
+// Sort discord entries based on their index
+func sortDiscordEntries(entries []*discordEntry) {
+	for i := 1; i < len(entries); i++ {
+		cur := entries[i]
+		j := i - 1
+
+		for j >= 0 && entries[j].Index > cur.Index {
+			entries[j+1] = entries[j]
+			j--
+		}
+		entries[j+1] = cur
+	}
 }
@@ -1,15 +1,18 @@
 package align_test
 
 import (
+	"encoding/json"
+	"io"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"testing"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/ethanbaker/align"
-	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,8 +20,7 @@ func TestDiscord(t *testing.T) {
 	require := require.New(t)
 
 	// Read in discord credentials
-	env, err := godotenv.Read("./config/discord/.env")
-	require.Nil(err)
+	env := readTestEnv(t, "./config/discord/.env")
 
 	// Start a discordgo session
 	session, err := discordgo.New("Bot " + env["DISCORD_TOKEN"])
@@ -34,7 +36,7 @@ func TestDiscord(t *testing.T) {
 	require.Nil(err)
 
 	// Initialize the discord module
-	align.InitDiscord(manager, session)
+	align.InitDiscord(manager, session, session.State.User.ID)
 
 	// Perform the contact
 	manager.OnContact()
@@ -58,8 +60,7 @@ func TestDiscordSQL(t *testing.T) {
 	require := require.New(t)
 
 	// Read in discord credentials
-	env, err := godotenv.Read("./config/discord/.env")
-	require.Nil(err)
+	env := readTestEnv(t, "./config/discord/.env")
 
 	// Start a discordgo session
 	session, err := discordgo.New("Bot " + env["DISCORD_TOKEN"])
@@ -75,7 +76,7 @@ func TestDiscordSQL(t *testing.T) {
 	require.Nil(err)
 
 	// Initialize the discord module
-	align.InitDiscord(manager, session)
+	align.InitDiscord(manager, session, session.State.User.ID)
 
 	// Perform the contact
 	manager.OnContact()
@@ -96,8 +97,7 @@ func TestDiscordPreSQL(t *testing.T) {
 	require := require.New(t)
 
 	// Read in discord credentials
-	env, err := godotenv.Read("./config/discord/.env")
-	require.Nil(err)
+	env := readTestEnv(t, "./config/discord/.env")
 
 	// Start a discordgo session
 	session, err := discordgo.New("Bot " + env["DISCORD_TOKEN"])
@@ -113,7 +113,7 @@ func TestDiscordPreSQL(t *testing.T) {
 	require.Nil(err)
 
 	// Initialize the discord module
-	align.InitDiscord(manager, session)
+	align.InitDiscord(manager, session, session.State.User.ID)
 
 	// Perform the contact
 	manager.OnContact()
@@ -125,8 +125,7 @@ func TestDiscordPostSQL(t *testing.T) {
 	require := require.New(t)
 
 	// Read in discord credentials
-	env, err := godotenv.Read("./config/discord/.env")
-	require.Nil(err)
+	env := readTestEnv(t, "./config/discord/.env")
 
 	// Start a discordgo session
 	session, err := discordgo.New("Bot " + env["DISCORD_TOKEN"])
@@ -142,7 +141,7 @@ func TestDiscordPostSQL(t *testing.T) {
 	require.Nil(err)
 
 	// Initialize the discord module
-	align.InitDiscord(manager, session)
+	align.InitDiscord(manager, session, session.State.User.ID)
 
 	// Send response with on completion
 	manager.OnCompletion()
@@ -153,8 +152,7 @@ func TestDiscordSQLMultiple(t *testing.T) {
 	require := require.New(t)
 
 	// Read in discord credentials
-	env, err := godotenv.Read("./config/discord/.env")
-	require.Nil(err)
+	env := readTestEnv(t, "./config/discord/.env")
 
 	// Start a discordgo session
 	session, err := discordgo.New("Bot " + env["DISCORD_TOKEN"])
@@ -170,7 +168,7 @@ func TestDiscordSQLMultiple(t *testing.T) {
 	require.Nil(err)
 
 	// Initialize the discord module
-	align.InitDiscord(manager, session)
+	align.InitDiscord(manager, session, session.State.User.ID)
 
 	for i := 0; i < 3; i++ {
 		log.Printf("[TEST]: testing iteration %v\n", i)
@@ -185,9 +183,295 @@ func TestDiscordSQLMultiple(t *testing.T) {
 		<-sc
 
 		// Initialize the discord module
-		align.InitDiscord(manager, session)
+		align.InitDiscord(manager, session, session.State.User.ID)
 
 		// Send response with on completion
 		manager.OnCompletion()
 	}
 }
+
+const discordChannelTestConfig = `
+settings:
+  title: "Group Meetup"
+  interval: 7
+  offset: 2
+  timezone: "America/New_York"
+  contact_time: "0 10 * * 0"
+  deadline_time: "0 10 * * 1"
+
+discord:
+  channel_id: "channel"
+
+persons:
+  - name: "Person 1"
+    request_method: "discord_channel"
+    response_method: "discord_channel"
+    id: "1"
+  - name: "Person 2"
+    request_method: "discord_channel"
+    response_method: "discord_channel"
+    id: "2"
+`
+
+func TestDiscordChannelRequest(t *testing.T) {
+	require := require.New(t)
+
+	align.ResetDiscordEntries()
+	t.Cleanup(align.ResetDiscordEntries)
+
+	person := align.Person{Name: "Person 1", RequestMethod: "discord_channel", ResponseMethod: "discord_channel", ID: "1"}
+
+	// Channel schedules need the discord module and a channel to post in
+	manager := createTestManager(t, managerTestConfig)
+	require.ErrorContains(align.DiscordChannelRequest(person, manager), "not been initialized")
+
+	align.InitDiscord(manager, &discordgo.Session{}, "")
+	require.ErrorContains(align.DiscordChannelRequest(person, manager), "channel ID is not set")
+
+	// The schedule is shared by everyone in the channel, so it isn't sent again once it has been posted
+	manager = createTestManager(t, discordChannelTestConfig)
+	align.InitDiscord(manager, &discordgo.Session{}, "")
+
+	align.AddDiscordEntry("", align.DiscordModeChannel, 0, "channel", "schedule")
+	require.Nil(align.DiscordChannelRequest(person, manager))
+}
+
+func TestDiscordFormatResponse(t *testing.T) {
+	require := require.New(t)
+
+	manager := createTestManager(t, discordChannelTestConfig)
+
+	// Every available day gets a field listing the persons free on it
+	embed := align.DiscordFormatResponse(manager, []string{"Person 2"}, 1, align.NewDay("2024-01-09", "Person 1"))
+	require.Equal("Schedule results for Group Meetup", embed.Title)
+	require.Contains(embed.Description, "1/2 people available")
+	require.Len(embed.Fields, 2)
+	require.Equal("Tuesday 01/09", embed.Fields[0].Name)
+	require.Equal("Person 1", embed.Fields[0].Value)
+	require.Equal("No responses from", embed.Fields[1].Name)
+	require.Equal("Person 2", embed.Fields[1].Value)
+
+	// Persons who didn't answer are only listed if there are any
+	embed = align.DiscordFormatResponse(manager, []string{}, 2, align.NewDay("2024-01-09", "Person 1", "Person 2"))
+	require.Len(embed.Fields, 1)
+	require.Equal("Person 1, Person 2", embed.Fields[0].Value)
+}
+
+// A request the test session sent
+type discordTestRequest struct {
+	Method string
+	Path   string
+	Body   []byte
+}
+
+// Sends every request of a test session to a function instead of discord
+type discordTestTransport func(*http.Request) (*http.Response, error)
+
+func (f discordTestTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// Create a discord session that records its requests instead of sending them
+func newDiscordTestSession(t *testing.T) (*discordgo.Session, *[]discordTestRequest) {
+	requests := &[]discordTestRequest{}
+
+	session, err := discordgo.New("Bot token")
+	require.Nil(t, err)
+
+	session.Client = &http.Client{Transport: discordTestTransport(func(r *http.Request) (*http.Response, error) {
+		body := []byte{}
+		if r.Body != nil {
+			body, _ = io.ReadAll(r.Body)
+		}
+		*requests = append(*requests, discordTestRequest{Method: r.Method, Path: r.URL.Path, Body: body})
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader("{}")),
+			Request:    r,
+		}, nil
+	})}
+
+	return session, requests
+}
+
+// Get the content of an interaction response or followup message the test session sent
+func discordResponseContent(t *testing.T, request discordTestRequest) string {
+	var response struct {
+		Content string `json:"content"`
+		Data    struct {
+			Content string `json:"content"`
+		} `json:"data"`
+	}
+	require.Nil(t, json.Unmarshal(request.Body, &response))
+
+	if response.Data.Content != "" {
+		return response.Data.Content
+	}
+	return response.Content
+}
+
+const discordComponentsTestConfig = `
+settings:
+  title: "Group Meetup"
+  interval: 7
+  offset: 2
+  timezone: "America/New_York"
+  contact_time: "0 10 * * 0"
+  deadline_time: "0 10 * * 1"
+
+persons:
+  - name: "Person 1"
+    request_method: "discord_components"
+    response_method: "discord"
+    id: "1"
+  - name: "Person 2"
+    request_method: "discord_components"
+    response_method: "discord"
+    id: "2"
+`
+
+func TestDiscordComponentInteraction(t *testing.T) {
+	require := require.New(t)
+
+	align.ResetDiscordEntries()
+	t.Cleanup(align.ResetDiscordEntries)
+
+	manager := createTestManager(t, discordComponentsTestConfig)
+	setContactDay(manager)
+	dates := manager.Timestamps()
+
+	session, requests := newDiscordTestSession(t)
+	align.AddDiscordComponentsEntry("Person 1", 0, "nnnnnnn")
+
+	choose := func(userID string, customID string, values ...string) {
+		align.HandleDiscordComponent(manager, session, &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+			ID:    "interaction",
+			Type:  discordgo.InteractionMessageComponent,
+			Token: "token",
+			User:  &discordgo.User{ID: userID},
+			Data:  discordgo.MessageComponentInteractionData{CustomID: customID, Values: values},
+		}})
+	}
+
+	// Dates chosen in the first menu are available and dates chosen in the second are tentative
+	choose("1", "align_yes:0", "0", "2")
+	require.Equal("ynynnnn", align.DiscordSelection("Person 1", 0))
+	require.True(manager.Availability("Person 1")[dates[2]])
+	require.Contains(discordResponseContent(t, (*requests)[0]), "✅ Tuesday 01/09\n⬜ Wednesday 01/10\n")
+
+	// Choosing a date in the other menu moves it, and dates left out of a menu are cleared
+	choose("1", "align_maybe:0", "2", "3")
+	require.Equal("ynmmnnn", align.DiscordSelection("Person 1", 0))
+	require.False(manager.Availability("Person 1")[dates[2]])
+	require.True(manager.Tentative("Person 1")[dates[2]])
+
+	choose("1", "align_yes:0")
+	require.Equal("nnmmnnn", align.DiscordSelection("Person 1", 0))
+	require.False(manager.Availability("Person 1")[dates[0]])
+
+	// Other menus are ignored
+	*requests = nil
+	choose("1", "other:0", "1")
+	require.Empty(*requests)
+
+	// Unknown users and menus without an entry are answered without changing anything
+	choose("3", "align_yes:0", "1")
+	require.Equal("You are not part of this schedule", discordResponseContent(t, (*requests)[0]))
+
+	choose("2", "align_yes:0", "1")
+	require.Equal("This schedule is closed", discordResponseContent(t, (*requests)[1]))
+	require.Equal("nnmmnnn", align.DiscordSelection("Person 1", 0))
+}
+
+func TestDiscordComponents(t *testing.T) {
+	require := require.New(t)
+
+	manager := createTestManager(t, discordComponentsTestConfig)
+	dates := []string{"2024-01-09", "2024-01-10", "2024-01-11"}
+
+	// Both menus list every date, with the dates in their state chosen by default
+	components := align.DiscordComponents(manager, 0, "ynm", dates)
+	require.Len(components, 2)
+
+	yes := components[0].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	require.Equal("align_yes:0", yes.CustomID)
+	require.Equal(3, yes.MaxValues)
+	require.Equal("Wednesday 01/10", yes.Options[1].Label)
+	require.Equal("1", yes.Options[1].Value)
+	require.True(yes.Options[0].Default)
+	require.False(yes.Options[2].Default)
+
+	maybe := components[1].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	require.Equal("align_maybe:0", maybe.CustomID)
+	require.True(maybe.Options[2].Default)
+	require.False(maybe.Options[0].Default)
+}
+
+func TestDiscordCommand(t *testing.T) {
+	require := require.New(t)
+
+	align.ResetDiscordEntries()
+	t.Cleanup(align.ResetDiscordEntries)
+
+	manager := createTestManager(t, discordComponentsTestConfig)
+	session, requests := newDiscordTestSession(t)
+
+	command := func(userID string, name string) string {
+		*requests = nil
+		align.HandleDiscordCommand(manager, session, &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+			ID:    "interaction",
+			Type:  discordgo.InteractionApplicationCommand,
+			Token: "token",
+			User:  &discordgo.User{ID: userID},
+			Data: discordgo.ApplicationCommandInteractionData{Name: "align", Options: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: name, Type: discordgo.ApplicationCommandOptionSubCommand},
+			}},
+		}})
+
+		// Commands that take longer to answer are deferred and answered with a followup message
+		require.NotEmpty(*requests)
+		return discordResponseContent(t, (*requests)[len(*requests)-1])
+	}
+
+	// Commands need a known person and a running schedule
+	require.Equal("You are not part of this schedule", command("3", "status"))
+	require.Equal("There are no results yet", command("1", "results"))
+	require.Equal("There is no schedule running right now", command("1", "status"))
+
+	setContactDay(manager)
+
+	// The status lists the person's answer and who is still pending
+	status := command("1", "status")
+	require.Contains(status, "You haven't answered yet")
+	require.Contains(status, "Still waiting on: Person 1, Person 2")
+
+	// Opting out removes the person from the pending list
+	require.Contains(command("1", "optout"), "You opted out of the schedule for Group Meetup")
+
+	status = command("1", "status")
+	require.Contains(status, "You opted out of this schedule")
+	require.Contains(status, "Still waiting on: Person 2")
+
+	// Changing your availability opts you back in, with menus holding your current answer
+	manager.ResetAvailability("Person 1")
+	align.AddDiscordComponentsEntry("Person 2", 0, "nnnnnnn")
+	align.HandleDiscordComponent(manager, session, &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:    "interaction",
+		Type:  discordgo.InteractionMessageComponent,
+		Token: "token",
+		User:  &discordgo.User{ID: "2"},
+		Data:  discordgo.MessageComponentInteractionData{CustomID: "align_yes:0", Values: []string{"1"}},
+	}})
+
+	require.Contains(command("2", "availability"), "Pick the dates you are free")
+	require.Equal("nynnnnn", align.DiscordSelection("Person 2", 0))
+
+	require.Contains(command("1", "availability"), "Pick the dates you are free")
+	require.Equal("nnnnnnn", align.DiscordSelection("Person 1", 0))
+
+	status = command("2", "status")
+	require.Contains(status, "⬜ Tuesday 01/09\n✅ Wednesday 01/10\n")
+	require.Contains(status, "Still waiting on: Person 1")
+}
@@ -7,11 +7,22 @@ settings described below:
 settings:
 
 	title: "Group Meetup"        # Title of the event
+	locale: "en"                 # Language messages are sent in ('en', 'de', 'es' or 'fr')
 	interval: 7                  # How many days to ask for availability
 	offset: 2                    # How many days past the contact time to ask for availability
+	range: "next week"           # Optional window of dates to ask about, replacing interval and offset
+	weekdays: ["Fri", "Sat"]     # Only ask about these weekdays (every weekday if empty)
+	exclude: ["2024-12-25"]      # Dates and date ranges ('2024-12-24..2024-12-31') to never ask about
+	holidays: "holidays.yml"     # iCalendar or YAML file of holidays to never ask about
 	timezone: "America/New_York" # Timezone that cron strings are based on
 	contact_time: "0 10 * * 0"   # Contact time cron string (Sunday at 10:00 AM)
 	deadline_time: "0 10 * * 1"  # Deadline time cron string (Monday at 10:00 AM)
+	quiet_hours: "22:00-08:00"   # Hours persons are never messaged in, on their own clock
+	attach_calendar: true        # Attach an iCalendar file for the best day to results
+	confirm: true                # Ask persons whether they are going once a day is decided on
+	auto_pick: true              # Decide on the best day automatically at the deadline
+	event_time: "18:00"          # Time the decided event starts at
+	reminders: ["1 day before"]  # When to remind attendees of the decided event
 
 persons:
 
@@ -19,18 +30,183 @@ persons:
     request_method: "discord"    # Method to request information from
     response_method: "discord"   # Method to respond with information
     id: "PERSONS_ID"             # Identifiying string for the person (Discord ID, Telegram ID, etc.)
+    timezone: "Europe/Berlin"    # Optional timezone of the person, if it differs from the group's
+    contact_time: "0 18 * * 0"   # Optional contact time cron string, if it differs from the group's
+    quiet_hours: "23:00-09:00"   # Optional quiet hours, if they differ from the group's
 
   - name: "Person 2"
     ...
 
 ```
 
+Instead of an interval and offset, the dates persons are asked about can be given as a `range`:
+* `next week` asks about the next calendar week, from Monday to Sunday
+* `rest of this week` and `rest of this month` ask about the days after the contact day until the week or month ends
+* `next month` asks about every day of the next month
+* `from +2d for 10d` asks about 10 days starting 2 days after the contact day (`w` can be used for weeks)
+
+Persons who live in another timezone can set their own `timezone`. They are contacted when the contact time comes
+around on their own clock (or right away, if it already passed there when the schedule starts), and deadlines and event
+times are shown in their local time. Dates are still aligned on the group's calendar, so everyone answers for the same
+days.
+
+Persons can also be contacted at their own `contact_time`. No messages are sent during a person's quiet hours:
+requests, results, announcements and reminders that would be sent then are held back until the quiet hours end. Held
+messages are stored with SQL, so they are still sent if align restarts, and requests and results held for a schedule
+are dropped when the next one starts. A request is never held past the deadline, so it is sent during quiet hours if
+it would otherwise arrive too late.
+
+Messages are sent in the group's `locale`, which also sets how dates and weekday names are written. The manager's
+FormatDate method formats a date the same way. Dates given to the manager's Decide method are always ISO dates.
+
 Currently, the `request_method` and `response_methods` must be the same value, but this will be changed in future updates.
 
 Examples for each module can be found in the 'examples/' directory. These directories contain the most barebones setup
 align needs to function. If you are using align in a more complicated package, you can provide the same types in the
 examples to get align working.
 
+## Templates
+
+Groups can replace the default messages with their own text/template templates. Templates are checked when the config
+is loaded, so a typo in a field name stops align from starting instead of breaking a message later on:
+
+```yaml
+settings:
+
+	templates:
+	  header: "{{ .Title }}: answer by {{ .Deadline }}"
+	  request_body: "{{ range .Dates }}{{ .Emoji }} {{ .Date }}\n{{ end }}"
+	  reminder: "{{ .Title }} is on {{ .Date }} with {{ join .Attendees \", \" }}"
+	  result: "{{ .Available }}/{{ .Total }} available on {{ range .Dates }}{{ .Date }} {{ end }}"
+	  no_result: "No day works this time"
+
+```
+
+Every template is given a MessageData with the title, dates, attendees, unknowns and counts of the message. The
+request body is only used for Discord reactions, as Telegram polls list their dates as options.
+
+On Discord, results are sent as an embed with a field for every best day, and result templates replace the fields. On
+Telegram, messages are formatted with HTML, and names and template output are escaped so they show up as written.
+
+## Heatmap
+
+Results come with a heatmap of everyone's availability, with a row for every person and a column for every date. Dates
+a person is free on are green, dates they might be free on are yellow, and dates they aren't free on are red. Persons
+who didn't answer are grey, and the best days are outlined. The heatmap of the last results can also be read with the
+manager's Heatmap method.
+
+Rows are labelled with a small built-in font that only has letters without accents and digits, so other characters
+are left out of the labels. Persons whose names have none of these characters, such as names in other scripts, are
+labelled with the number of their row, counting persons in the order of the config file.
+
+## Holidays
+
+Dates in `exclude` and holidays in the `holidays` file are left out of every request, and the results list the dates
+that weren't asked about. The holiday file can be an iCalendar file, where every event is a holiday, or a YAML file:
+
+```yaml
+  - name: "Christmas"
+    date: "2024-12-25"
+  - name: "Winter break"
+    date: "2024-12-24..2024-12-31"
+
+```
+
+## Confirmations
+
+Results only show which days work, so align can also run a second round to lock in a date. With `confirm` set, the
+organizer decides on a day by calling the manager's Decide method with one of the schedule's dates (or lets align pick
+the best day at the deadline with `auto_pick`):
+
+```go
+err := manager.Decide("2024-01-06")
+```
+
+Align then tells every person which day was decided on using their response method. With `confirm` set, the message
+asks whether they are going with buttons. Replies are tracked until the day of the event is over, and can be checked
+with `/align event` on Discord or `/event` on Telegram.
+
+Once a day is decided on, align reminds everyone attending it at the times listed in `reminders`. Reminders are written
+as '1 day before', '2 hours before' or '30 minutes before' (or as durations such as '90m'), counting back from
+`event_time` on the decided day, or from midnight if no time is set. If persons confirm, only those going are reminded.
+The decision and the reminders already sent are stored with SQL, so reminders are rescheduled when align restarts, and
+reminders missed while align was down are sent as soon as the modules persons are reminded with are initialized.
+
+Persons can also be made organizers. When a group has organizers, results are first sent to the organizers in a
+private message along with a menu of every date in the schedule (the best days are starred). Once an organizer picks a
+day, align sends the results to everyone and announces the decision. Only the first pick counts, and results held back
+for organizers are stored with SQL so they can still pick after align restarts. `auto_pick` has no effect when a group
+has organizers.
+
+```yaml
+persons:
+
+  - name: "Person 1"
+    ...
+    organizer: true # Pick the final day before results are sent to everyone
+
+```
+
+## Calendars
+
+Setting `attach_calendar: true` in the settings attaches an iCalendar (.ics) file for the best day to every result
+message, so persons can add the meetup to their calendars with one click. The event lasts the whole day, or starts at
+`event_time` in the group's timezone when it is set. The same file can be served over HTTP with the manager's
+CalendarHandler:
+
+```go
+http.Handle("/align.ics", manager.CalendarHandler())
+```
+
+Persons can also provide a calendar to pre-fill their availability. The calendar can be an iCalendar URL or a local
+iCalendar file:
+
+```yaml
+persons:
+
+  - name: "Person 1"
+    request_method: "discord"
+    response_method: "discord"
+    id: "PERSONS_ID"
+    calendar: "https://example.com/person1.ics" # Calendar used to pre-fill availability
+
+```
+
+Before a person is contacted, align reads their calendar and marks every date with an event as busy. The request shows
+these pre-filled answers, and if the person doesn't answer by the deadline, they are treated as free on every date
+their calendar is free. Events marked as free (transparent) or cancelled are ignored, and recurring events only count
+for their first occurrence.
+
+## CalDAV
+
+Align can also read availability from, and write the chosen event to, a CalDAV server. Provide the server's credentials
+in the configuration file, and give each person the path of their calendar on the server:
+
+```yaml
+caldav:
+
+	url: "https://dav.example.com"   # Base URL of the CalDAV server
+	username: CALDAV_USER
+	password: CALDAV_PASSWORD
+	calendar: "/calendars/group/"   # Shared calendar the chosen event is written to
+
+persons:
+
+  - name: "Person 1"
+    ...
+    caldav: "/calendars/person1/" # Calendar used to pre-fill availability
+
+```
+
+Then initialize the module with the HTTP client align should use:
+
+```go
+align.InitCalDAV(manager, http.DefaultClient)
+```
+
+Busy times are read the same way as iCalendar files (see above). Once the deadline passes, the best day is written to
+the shared calendar as an event.
+
 ## SQL
 
 Align has an option to use SQL to store availability data. This is useful if align ever stops running (server resetting,
@@ -60,6 +236,31 @@ with said user. This is a limitation of the Discord API, and align cannot bypass
 To collect Discord IDs, you can right click on a profile you want to contact and click 'Copy User ID.' You can provide
 this information to align's configuration file.
 
+Persons can also use the `discord_components` request method (with the `discord` response method). Instead of emoji
+reactions, align sends select menus where the person picks the dates they are free and the dates they might be free.
+Every change is saved right away and confirmed with a message only that person can see. Dates marked as maybe don't
+count towards the number of people available, but are listed in the results.
+
+InitDiscord registers an `/align` slash command for the given application ID, which is the ID of the bot's user
+(`session.State.User.ID` once the session is open). The command lets persons manage their schedule without waiting
+for the bot to message them:
+* `/align status` shows what you answered and who hasn't answered yet
+* `/align availability` lets you change your answer using select menus
+* `/align optout` opts you out of the current schedule
+* `/align results` shows the results of the last schedule
+
+If your group shares a Discord server, persons can instead use the `discord_channel` method. Align will post a single
+schedule in a guild channel, read each person's reactions using their Discord ID, and post the results in the same
+channel (or in a thread started from the schedule, if `thread` is set). The channel is configured as follows:
+
+```yaml
+discord:
+
+	channel_id: "CHANNEL_ID" # Guild channel to post schedules in
+	thread: true             # Whether to post results in a thread off of the schedule
+
+```
+
 ## Telegram
 
 To initialize telegram with align, you can start a telegram session using [telegram-bot-api](https://github.com/go-telegram-bot-api/telegram-bot-api).
@@ -78,5 +279,47 @@ The best way to do this in practice is to approach the user you want to contact
 conversation with the bot while the bot is online (or during the 24 hour update period). This way, the bot can send
 messages to the user without any issues. Secondly, you need to receive this user's Telegram User ID (not username). This can
 be done by having that user message '@userinfobot', clicking 'start', and recording the 'User Id Information' field.
+
+Telegram polls can only show a handful of dates and can't show what a person has already picked. Persons can instead
+use the `telegram_keyboard` request method (with the `telegram` response method), which sends a message with a button
+for every date. Tapping a date cycles it between free (✅), maybe (❔) and busy (⬜), and pressing 'Done' saves the
+selection. Dates marked as maybe don't count towards the number of people available, but are listed in the results.
+
+Persons can also message the bot with commands to manage their schedule:
+* `/status` shows the current schedule and who hasn't answered yet
+* `/myavailability` shows what you answered
+* `/skip` opts you out of the current schedule
+* `/results` shows the results of the last schedule
+* `/help` lists all commands
+
+If your group shares a Telegram group chat, persons can instead use the `telegram_group` method. Align will post a
+single non-anonymous poll in the group, attribute each vote to a person using their Telegram User ID, and reply with
+the results in the same chat. The bot must be a member of the group. The chat is configured as follows:
+
+```yaml
+telegram:
+
+	chat_id: -1001234567890 # Group chat to post polls in
+
+```
+
+## Testing
+
+InitDiscord and InitTelegram accept any DiscordSession or TelegramSession, which cover the calls align makes to each
+API. A *discordgo.Session or *telegram.BotAPI can be passed as is, while tests can pass an in-memory fake that records
+the messages align sends and replays scripted reactions and poll answers. This way a whole schedule, from contact to
+completion, can be run without any bot tokens.
+
+Managers tell time and run their jobs with the clock given in the options, or the system clock if none is given. A
+FakeClock only moves when Advance or Set is called, running the contact, deadline, reminder and held back messages
+that are due along the way, so a whole week can be simulated in a test:
+
+	clock := align.NewFakeClock(time.Date(2024, time.January, 6, 12, 0, 0, 0, loc))
+	manager, err := align.CreateManager("group", "./config.yml", align.Options{Clock: clock})
+
+	clock.Advance(7 * 24 * time.Hour)
+
+The tests in discord_test.go and telegram_test.go talk to the real APIs, and are skipped unless credentials are
+found in './config/discord/.env' and './config/telegram/.env'.
 */
 package align
@@ -39,7 +39,7 @@ func main() {
 
 	// Initialize the modules
 	align.InitTelegram(manager, telegramSession)
-	align.InitDiscord(manager, discordSession)
+	align.InitDiscord(manager, discordSession, discordSession.State.User.ID)
 
 	// Perform the contact
 	manager.OnContact()
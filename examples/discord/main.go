@@ -32,7 +32,7 @@ func main() {
 	}
 
 	// Initialize module
-	align.InitDiscord(manager, session)
+	align.InitDiscord(manager, session, session.State.User.ID)
 
 	// Wait here until CTRL-C or other term signal is received.
 	log.Println("Align is now running. Press CTRL-C to exit.")
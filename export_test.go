@@ -0,0 +1,206 @@
+package align
+
+import (
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	telegram "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Unexported functions used by the tests in align_test
+
+// Modes a discord entry can be sent with
+const (
+	DiscordModeDirect  = discordModeDirect
+	DiscordModeChannel = discordModeChannel
+)
+
+// NewDay creates an available day for a timestamp
+func NewDay(timestamp string, available ...string) day {
+	return day{Timestamp: timestamp, AvailablePersons: available}
+}
+
+// DiscordFormatResponse formats the discord results embed
+func DiscordFormatResponse(m *Manager, unknowns []string, available int, days ...day) *discordgo.MessageEmbed {
+	return discordFormatResponse(m, days, unknowns, available)
+}
+
+// AddDiscordEntry records a discord entry as if its message had been sent
+func AddDiscordEntry(person string, mode string, index int, channelID string, messageID string) {
+	discordEntries = append(discordEntries, &discordEntry{Person: person, Mode: mode, Index: index, ChannelID: channelID, MessageID: messageID})
+}
+
+// ResetDiscordEntries forgets every discord entry
+func ResetDiscordEntries() {
+	discordEntries = nil
+}
+
+// Timestamps returns the timestamps persons are asked about for the current contact day
+func (m *Manager) Timestamps() []string {
+	return m.generateTimestamps()
+}
+
+// Modes a telegram entry can be sent with
+const (
+	TelegramModeDirect = telegramModeDirect
+	TelegramModeGroup  = telegramModeGroup
+)
+
+// HandleTelegramPollAnswer updates a person's availability from a poll answer
+var HandleTelegramPollAnswer = telegramHandlePollAnswer
+
+// HandleTelegramPoll updates a person's availability from the tally of an anonymous poll
+var HandleTelegramPoll = telegramHandlePoll
+
+// TelegramFormatResponse formats the telegram results message
+func TelegramFormatResponse(m *Manager, unknowns []string, available int, days ...day) string {
+	return telegramFormatResponse(m, days, unknowns, available)
+}
+
+// AddTelegramEntry records a telegram entry as if its poll had been sent
+func AddTelegramEntry(person string, mode string, index int, pollID string, chatID int64, messageID int) {
+	telegramEntries = append(telegramEntries, &telegramEntry{Person: person, Mode: mode, Index: index, PollID: pollID, ChatID: chatID, MessageID: messageID})
+}
+
+// ResetTelegramEntries forgets every telegram entry
+func ResetTelegramEntries() {
+	telegramEntries = nil
+}
+
+// ResetAvailability marks the person with the given name as unavailable on every date, as if they had been asked
+func (m *Manager) ResetAvailability(name string) {
+	m.edit.Lock()
+	defer m.edit.Unlock()
+
+	m.availability[name] = m.generateAvailability()
+}
+
+// Availability returns the current availability of the person with the given name
+func (m *Manager) Availability(name string) map[string]bool {
+	m.edit.Lock()
+	defer m.edit.Unlock()
+
+	availability := map[string]bool{}
+	for date, available := range m.availability[name] {
+		availability[date] = available
+	}
+
+	return availability
+}
+
+// TelegramModeKeyboard is the mode of entries sent with an inline keyboard
+const TelegramModeKeyboard = telegramModeKeyboard
+
+// HandleTelegramCallback updates a person's keyboard selection from a button press
+var HandleTelegramCallback = telegramHandleCallback
+
+// AddTelegramKeyboardEntry records a telegram keyboard entry as if its message had been sent
+func AddTelegramKeyboardEntry(person string, index int, chatID int64, messageID int, selection string) {
+	telegramEntries = append(telegramEntries, &telegramEntry{Person: person, Mode: telegramModeKeyboard, Index: index, ChatID: chatID, MessageID: messageID, Selection: selection})
+}
+
+// TelegramSelection returns the keyboard selection of the telegram entry sent to the given message
+func TelegramSelection(chatID int64, messageID int) string {
+	for _, e := range telegramEntries {
+		if e.ChatID == chatID && e.MessageID == messageID {
+			return e.Selection
+		}
+	}
+
+	return ""
+}
+
+// TelegramKeyboardMarkup builds the inline keyboard for a keyboard selection
+func TelegramKeyboardMarkup(m *Manager, index int, selection string, dates []string) telegram.InlineKeyboardMarkup {
+	return telegramKeyboardMarkup(m, &telegramEntry{Index: index, Selection: selection}, dates)
+}
+
+// Tentative returns the dates the person with the given name might be available on
+func (m *Manager) Tentative(name string) map[string]bool {
+	m.edit.Lock()
+	defer m.edit.Unlock()
+
+	tentative := map[string]bool{}
+	for date, maybe := range m.tentative[name] {
+		tentative[date] = maybe
+	}
+
+	return tentative
+}
+
+// Align finds the days at least n persons are available on
+var Align = align
+
+// DiscordModeComponents is the mode of entries sent with select menus
+const DiscordModeComponents = discordModeComponents
+
+// HandleDiscordComponent updates a person's component selection from a select menu interaction
+var HandleDiscordComponent = discordHandleComponent
+
+// AddDiscordComponentsEntry records a discord components entry as if its message had been sent
+func AddDiscordComponentsEntry(person string, index int, selection string) {
+	discordEntries = append(discordEntries, &discordEntry{Person: person, Mode: discordModeComponents, Index: index, Selection: selection})
+}
+
+// DiscordSelection returns the component selection of the person's discord entry with the given index
+func DiscordSelection(person string, index int) string {
+	for _, e := range discordEntries {
+		if e.Person == person && e.Index == index {
+			return e.Selection
+		}
+	}
+
+	return ""
+}
+
+// DiscordComponents builds the select menus for a component selection
+func DiscordComponents(m *Manager, index int, selection string, dates []string) []discordgo.MessageComponent {
+	return discordComponents(m, &discordEntry{Index: index, Selection: selection}, dates)
+}
+
+// HandleDiscordCommand handles the '/align' slash command
+var HandleDiscordCommand = discordHandleCommand
+
+// HandleTelegramCommand replies to a command sent by a person
+var HandleTelegramCommand = telegramHandleCommand
+
+// Pick locks in the event for a date the way an organizer picks it from results held back for them
+func (m *Manager) Pick(timestamp string) error {
+	return m.decide(timestamp, true)
+}
+
+var (
+	ReadCalendar     = readCalendar
+	ParseBusy        = parseBusy
+	ParseICSTime     = parseICSTime
+	ParseICSDuration = parseICSDuration
+)
+
+// CalendarDefaults generates the default availability of the person with the given name from their calendars
+func (m *Manager) CalendarDefaults(name string) (map[string]bool, error) {
+	person, _ := m.person(name)
+	return m.calendarDefaults(person)
+}
+
+// CalDAVBusy reads the busy spans from the CalDAV calendar of the person with the given name
+func (m *Manager) CalDAVBusy(name string) ([]busy, bool, error) {
+	person, _ := m.person(name)
+	return caldavBusy(person, m)
+}
+
+// Dates returns the dates persons are asked about for the current contact day
+func (m *Manager) Dates() []time.Time {
+	return m.generateDates()
+}
+
+// ContactTime finds when the person with the given name is contacted for a schedule started at the given time
+func (m *Manager) ContactTime(name string, start time.Time) (time.Time, error) {
+	person, _ := m.person(name)
+	return m.contactTime(person, start)
+}
+
+// EventWhen describes when an event takes place on the clock of the person with the given name
+func (m *Manager) EventWhen(event Event, name string) string {
+	person, _ := m.person(name)
+	return m.eventWhen(event, person)
+}
@@ -0,0 +1,702 @@
+package align_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ethanbaker/align"
+	"github.com/stretchr/testify/require"
+)
+
+const harnessTestConfig = `
+settings:
+  title: "Group Meetup"
+  interval: 7
+  offset: 2
+  timezone: "America/New_York"
+  contact_time: "0 10 * * 0"
+  deadline_time: "0 10 * * 1"
+
+persons:
+  - name: "Person 1"
+    request_method: "discord"
+    response_method: "discord"
+    id: "1"
+  - name: "Person 2"
+    request_method: "discord"
+    response_method: "discord"
+    id: "2"
+  - name: "Person 3"
+    request_method: "telegram"
+    response_method: "telegram"
+    id: "3"
+  - name: "Person 4"
+    request_method: "telegram"
+    response_method: "telegram"
+    id: "4"
+`
+
+const cycleTestConfig = `
+settings:
+  title: "Group Meetup"
+  interval: 7
+  offset: 2
+  timezone: "America/New_York"
+  contact_time: "0 10 * * 0"
+  deadline_time: "0 10 * * 1"
+  event_time: "18:30"
+  reminders: ["1 day before"]
+
+persons:
+  - name: "Person 1"
+    request_method: "discord"
+    response_method: "discord"
+    id: "1"
+  - name: "Person 2"
+    request_method: "discord"
+    response_method: "discord"
+    id: "2"
+`
+
+// Run a whole schedule against fake discord and telegram sessions: persons are contacted, vote, and are sent the
+// results
+func TestHarness(t *testing.T) {
+	require := require.New(t)
+
+	manager := createTestManager(t, harnessTestConfig)
+
+	discord := newFakeDiscord()
+	align.InitDiscord(manager, discord, "app")
+
+	// The '/align' command is registered for the application
+	require.Len(discord.commands["app"], 1)
+	require.Equal("align", discord.commands["app"][0].Name)
+
+	telegram := newFakeTelegram(t)
+	align.InitTelegram(manager, telegram)
+
+	// Contact everyone
+	manager.OnContact()
+
+	// Discord persons get a header and a schedule message with a reaction for every date
+	for _, id := range []string{"1", "2"} {
+		messages := discord.Messages("dm-" + id)
+		require.Len(messages, 2)
+		require.Contains(messages[0].Content, "Group Meetup")
+		require.Contains(messages[1].Content, "1️⃣")
+	}
+
+	// Telegram persons get a poll with every date
+	polls := map[int64]string{}
+	var dates []string
+	for _, id := range []int64{3, 4} {
+		messages := telegram.Messages(id)
+		require.Len(messages, 1)
+		require.NotEmpty(messages[0].PollID)
+		require.Len(messages[0].Options, 7)
+
+		polls[id] = messages[0].PollID
+		dates = messages[0].Options
+	}
+
+	// Everyone but Person 4 can make it on the second date
+	discord.React(discord.Messages("dm-1")[1].ID, "1️⃣", "1")
+	discord.React(discord.Messages("dm-1")[1].ID, "2️⃣", "1")
+	discord.React(discord.Messages("dm-2")[1].ID, "2️⃣", "2")
+	telegram.Answer(polls[3], 3, 1, 2)
+
+	// Votes from persons outside the group are ignored
+	telegram.Answer(polls[4], 99, 0)
+
+	manager.OnCompletion()
+
+	// Discord persons get an embed listing the best day, with the heatmap attached
+	for _, id := range []string{"1", "2"} {
+		messages := discord.Messages("dm-" + id)
+		require.Len(messages, 3)
+
+		result := messages[2]
+		require.Len(result.Embeds, 1)
+		require.Equal("3/4 people available", result.Embeds[0].Description)
+		require.Equal(dates[1], result.Embeds[0].Fields[0].Name)
+		require.ElementsMatch([]string{"Person 1", "Person 2", "Person 3"}, strings.Split(result.Embeds[0].Fields[0].Value, ", "))
+		require.Equal("No responses from", result.Embeds[0].Fields[1].Name)
+		require.Equal("Person 4", result.Embeds[0].Fields[1].Value)
+		require.Equal([]string{"heatmap.png"}, result.Files)
+	}
+
+	// Telegram persons get the heatmap captioned with the results, and their polls are closed
+	for _, id := range []int64{3, 4} {
+		messages := telegram.Messages(id)
+		require.Len(messages, 2)
+		require.True(messages[1].Photo)
+		require.Contains(messages[1].Text, dates[1])
+		require.Contains(messages[1].Text, "Person 4")
+	}
+	require.Len(telegram.stopped, 2)
+
+	result, ok := manager.Event()
+	require.True(ok)
+	require.ElementsMatch([]string{"Person 1", "Person 2", "Person 3"}, result.Attendees)
+}
+
+// Simulate a week on a fake clock: persons are contacted at the contact time, sent the results at the deadline,
+// reminded before the event, and contacted again the next week
+func TestWeeklyCycle(t *testing.T) {
+	require := require.New(t)
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.Nil(err)
+
+	// Start on the Saturday before the contact time
+	clock := align.NewFakeClock(time.Date(2024, time.January, 6, 12, 0, 0, 0, loc))
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.Nil(os.WriteFile(path, []byte(cycleTestConfig), 0o600))
+
+	manager, err := align.CreateManager("test-cycle", path, align.Options{
+		UseSQL: false,
+		Clock:  clock,
+	})
+	require.Nil(err)
+
+	discord := newFakeDiscord()
+	align.InitDiscord(manager, discord, "app")
+
+	// Nobody is contacted before the contact time
+	clock.Set(time.Date(2024, time.January, 7, 9, 59, 0, 0, loc))
+	require.Empty(discord.Messages("dm-1"))
+
+	// Persons are asked about the week starting two days after the contact day, and answer by the deadline
+	clock.Set(time.Date(2024, time.January, 7, 10, 0, 0, 0, loc))
+	messages := discord.Messages("dm-1")
+	require.Len(messages, 2)
+	require.Contains(messages[0].Content, "Monday 01/08 at 10:00")
+	require.Contains(messages[1].Content, "Tuesday 01/09")
+	require.Contains(messages[1].Content, "Monday 01/15")
+
+	discord.React(discord.Messages("dm-1")[1].ID, "2️⃣", "1")
+	discord.React(discord.Messages("dm-2")[1].ID, "2️⃣", "2")
+
+	// Results are sent at the deadline
+	clock.Set(time.Date(2024, time.January, 8, 10, 0, 0, 0, loc))
+	messages = discord.Messages("dm-1")
+	require.Len(messages, 3)
+	require.Equal("Wednesday 01/10", messages[2].Embeds[0].Fields[0].Name)
+
+	// Persons are reminded a day before the decided event starts
+	require.Nil(manager.Decide("2024-01-10"))
+	announced := len(discord.Messages("dm-1"))
+
+	clock.Set(time.Date(2024, time.January, 9, 18, 29, 0, 0, loc))
+	require.Len(discord.Messages("dm-1"), announced)
+
+	clock.Set(time.Date(2024, time.January, 9, 18, 30, 0, 0, loc))
+	messages = discord.Messages("dm-1")
+	require.Len(messages, announced+1)
+	require.Contains(messages[announced].Content, "Reminder")
+
+	// The next week's schedule starts at the next contact time
+	clock.Set(time.Date(2024, time.January, 14, 10, 0, 0, 0, loc))
+	messages = discord.Messages("dm-1")
+	require.Len(messages, announced+3)
+	require.Contains(messages[announced+2].Content, "Tuesday 01/16")
+}
+
+// Persons using the 'discord_components' method pick their dates from select menus
+func TestComponentsSchedule(t *testing.T) {
+	require := require.New(t)
+
+	manager := createTestManager(t, strings.Replace(managerTestConfig, `request_method: "discord"`, `request_method: "discord_components"`, 1))
+
+	discord := newFakeDiscord()
+	align.InitDiscord(manager, discord, "app")
+
+	manager.OnContact()
+
+	// The person gets a header and a message with a menu for free dates and a menu for maybe dates
+	messages := discord.Messages("dm-1")
+	require.Len(messages, 2)
+	require.Len(messages[1].Components, 2)
+
+	free := messages[1].Components[0].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	maybe := messages[1].Components[1].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	require.Equal("align_yes:0", free.CustomID)
+	require.Equal("align_maybe:0", maybe.CustomID)
+	require.Len(free.Options, 7)
+
+	// Every choice is confirmed only to the person
+	discord.Select("1", "dm-1", free.CustomID, "1")
+	discord.Select("1", "dm-1", maybe.CustomID, "2")
+
+	responses := discord.Responses()
+	require.Len(responses, 2)
+	require.Equal(discordgo.MessageFlagsEphemeral, responses[1].Data.Flags)
+	require.Contains(responses[1].Data.Content, free.Options[1].Label)
+
+	// The menus are closed at the deadline, and the results list the date the person is free on
+	manager.OnCompletion()
+
+	messages = discord.Messages("dm-1")
+	require.Len(messages, 3)
+
+	fields := messages[2].Embeds[0].Fields
+	require.Len(fields, 1)
+	require.Equal(free.Options[1].Label, fields[0].Name)
+	require.Equal("Person 1", fields[0].Value)
+}
+
+// Managers sharing a discord session each keep listening for interactions with their own menus
+func TestSharedDiscordSession(t *testing.T) {
+	require := require.New(t)
+
+	config := strings.Replace(managerTestConfig, `request_method: "discord"`, `request_method: "discord_components"`, 1)
+	first := createTestManager(t, config)
+	second := createTestManager(t, strings.Replace(config, `id: "1"`, `id: "2"`, 1))
+
+	discord := newFakeDiscord()
+	align.InitDiscord(first, discord, "app")
+	align.InitDiscord(first, discord, "app")
+	align.InitDiscord(second, discord, "app")
+
+	first.OnContact()
+
+	// Initializing a manager again replaces its own listener, so the choice is answered once by each manager
+	free := discord.Messages("dm-1")[1].Components[0].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	discord.Select("1", "dm-1", free.CustomID, "1")
+	require.Len(discord.Responses(), 2)
+
+	first.OnCompletion()
+
+	fields := discord.Messages("dm-1")[2].Embeds[0].Fields
+	require.Equal(free.Options[1].Label, fields[0].Name)
+	require.Equal("Person 1", fields[0].Value)
+}
+
+// Menus and keyboards from a schedule with more dates than the current one are closed instead of changing answers
+func TestClosedSchedule(t *testing.T) {
+	require := require.New(t)
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.Nil(err)
+
+	clock := align.NewFakeClock(time.Date(2024, time.January, 7, 10, 0, 0, 0, loc))
+
+	// The week after the first schedule is excluded, so the next schedule has no dates
+	config := withSettings(harnessTestConfig, "  exclude: [\"2024-01-16..2024-01-31\"]\n")
+	config = strings.Replace(config, `request_method: "discord"`, `request_method: "discord_components"`, 1)
+	config = strings.Replace(config, `request_method: "telegram"`, `request_method: "telegram_keyboard"`, 1)
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.Nil(os.WriteFile(path, []byte(config), 0o600))
+
+	manager, err := align.CreateManager("test-closed", path, align.Options{UseSQL: false, Clock: clock})
+	require.Nil(err)
+
+	discord := newFakeDiscord()
+	align.InitDiscord(manager, discord, "app")
+
+	telegram := newFakeTelegram(t)
+	align.InitTelegram(manager, telegram)
+
+	manager.OnContact()
+
+	menus := discord.Messages("dm-1")
+	require.Len(menus, 2)
+	free := menus[1].Components[0].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+
+	keyboards := telegram.Messages(3)
+	require.Len(keyboards, 1)
+
+	// A new schedule starts before the persons answer
+	manager.ContactDay.Time = time.Date(2024, time.January, 14, 10, 0, 0, 0, loc)
+
+	discord.Select("1", "dm-1", free.CustomID, "6")
+	responses := discord.Responses()
+	require.Len(responses, 1)
+	require.Equal("This schedule is closed", responses[0].Data.Content)
+
+	for _, data := range []string{"align:6", "align:done"} {
+		telegram.Press(3, keyboards[0].ID, 3, data)
+	}
+	require.Equal([]string{"This schedule is closed", "This schedule is closed"}, telegram.Callbacks())
+}
+
+// Results are held back until an organizer picks the final day, and the day can only be picked once
+func TestApproval(t *testing.T) {
+	require := require.New(t)
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.Nil(err)
+
+	clock := align.NewFakeClock(time.Date(2024, time.January, 7, 10, 0, 0, 0, loc))
+
+	// Person 1 organizes on discord and Person 3 on telegram
+	config := strings.Replace(harnessTestConfig, "    id: \"1\"\n", "    id: \"1\"\n    organizer: true\n", 1)
+	config = strings.Replace(config, "    id: \"3\"\n", "    id: \"3\"\n    organizer: true\n", 1)
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.Nil(os.WriteFile(path, []byte(config), 0o600))
+
+	manager, err := align.CreateManager("test-approval", path, align.Options{UseSQL: false, Clock: clock})
+	require.Nil(err)
+
+	discord := newFakeDiscord()
+	align.InitDiscord(manager, discord, "app")
+
+	telegram := newFakeTelegram(t)
+	align.InitTelegram(manager, telegram)
+
+	manager.OnContact()
+	manager.OnCompletion()
+
+	// Only organizers are sent the results until the final day is picked
+	require.True(manager.Awaiting)
+	require.NotNil(manager.LastResult)
+	require.Len(discord.Messages("dm-2"), 2)
+
+	approval := telegram.Messages(3)
+	message := approval[len(approval)-1]
+
+	// Picks carry the date they are for, so only dates of the current schedule can be picked
+	telegram.Press(3, message.ID, 3, "align:pick:2023-01-10")
+	require.Nil(manager.Decision)
+
+	telegram.Press(3, message.ID, 3, "align:pick:2024-01-10")
+	require.NotNil(manager.Decision)
+	require.Equal("2024-01-10", manager.Decision.Date.Format(align.DATE_FORMAT))
+	require.False(manager.Awaiting)
+	require.Greater(len(discord.Messages("dm-2")), 2)
+
+	// Once a day is picked, other picks are refused
+	discord.Select("1", "dm-1", "align_pick:date", "2024-01-11")
+	telegram.Press(3, message.ID, 3, "align:pick:2024-01-12")
+	require.Equal("2024-01-10", manager.Decision.Date.Format(align.DATE_FORMAT))
+
+	responses := discord.Responses()
+	require.Equal("The final day has already been picked", responses[len(responses)-1].Data.Content)
+	require.Equal([]string{"This schedule is closed", "Decided on Wednesday 01/10", "The final day has already been picked"}, telegram.Callbacks())
+}
+
+// The approval menu holds at most 25 options, even when more days are tied for the best
+func TestApprovalManyDays(t *testing.T) {
+	require := require.New(t)
+
+	config := strings.Replace(managerTestConfig, `request_method: "discord"`, `request_method: "discord_components"`, 1)
+	config = strings.Replace(config, "interval: 7", "interval: 30", 1)
+	config = strings.Replace(config, "    id: \"1\"\n", "    id: \"1\"\n    organizer: true\n", 1)
+
+	manager := createTestManager(t, config)
+
+	discord := newFakeDiscord()
+	align.InitDiscord(manager, discord, "app")
+
+	manager.OnContact()
+
+	// The person is free on every date, so all 30 days are tied
+	for _, message := range discord.Messages("dm-1")[1:] {
+		free := message.Components[0].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+
+		values := []string{}
+		for _, option := range free.Options {
+			values = append(values, option.Value)
+		}
+		discord.Select("1", "dm-1", free.CustomID, values...)
+	}
+
+	manager.OnCompletion()
+	require.Len(manager.LastResult.Days, 30)
+
+	messages := discord.Messages("dm-1")
+	approval := messages[len(messages)-1].Components[0].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	require.Equal("align_pick:date", approval.CustomID)
+	require.Len(approval.Options, 25)
+	require.Equal(manager.LastResult.Days[0].Timestamp, approval.Options[0].Value)
+	require.Equal(manager.LastResult.Days[24].Timestamp, approval.Options[24].Value)
+}
+
+// Reminders missed while align wasn't running are held back until the modules they are sent with are initialized
+func TestMissedReminder(t *testing.T) {
+	require := require.New(t)
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.Nil(err)
+
+	// The reminder a day before the event was due half an hour ago
+	clock := align.NewFakeClock(time.Date(2024, time.January, 9, 19, 0, 0, 0, loc))
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.Nil(os.WriteFile(path, []byte(withSettings(cycleTestConfig, "  confirm: true\n")), 0o600))
+
+	manager, err := align.CreateManager("test-missed", path, align.Options{UseSQL: false, Clock: clock})
+	require.Nil(err)
+
+	// The event was decided on before align stopped
+	manager.ContactDay = sql.NullTime{Time: time.Date(2024, time.January, 7, 10, 0, 0, 0, loc), Valid: true}
+	require.Nil(manager.Decide("2024-01-10"))
+	manager.Confirmations["Person 1"] = true
+
+	// The reminder isn't used up before it can be sent
+	require.Never(func() bool { return len(manager.Reminded) > 0 }, 50*time.Millisecond, 10*time.Millisecond)
+
+	discord := newFakeDiscord()
+	align.InitDiscord(manager, discord, "app")
+
+	require.Eventually(func() bool {
+		messages := discord.Messages("dm-1")
+		return len(messages) == 1 && strings.Contains(messages[0].Content, "Reminder")
+	}, time.Second, 10*time.Millisecond)
+	require.Empty(discord.Messages("dm-2"))
+}
+
+// Messages are held back during quiet hours, which are on a person's own clock and can be set for the whole group or
+// for a single person
+func TestQuietHours(t *testing.T) {
+	require := require.New(t)
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.Nil(err)
+
+	// Results are sent on a Tuesday, away from the contact and deadline times
+	at := func(day int, hour int, minute int) time.Time {
+		return time.Date(2024, time.January, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name     string
+		group    string    // The group's quiet hours
+		person   string    // The person's own settings
+		now      time.Time // When the results are sent
+		expected time.Time // When the person gets the results, or the zero time if right away
+	}{
+		{"outside same-day window", "13:00-14:00", "", at(9, 12, 0), time.Time{}},
+		{"same-day window", "13:00-14:00", "", at(9, 13, 30), at(9, 14, 0)},
+		{"outside window over midnight", "22:00-08:00", "", at(9, 12, 0), time.Time{}},
+		{"before midnight", "22:00-08:00", "", at(9, 23, 0), at(10, 8, 0)},
+		{"after midnight", "22:00-08:00", "", at(10, 7, 0), at(10, 8, 0)},
+		{"person override outside window", "22:00-08:00", "    quiet_hours: \"13:00-14:00\"\n", at(9, 23, 0), time.Time{}},
+		{"person override", "22:00-08:00", "    quiet_hours: \"13:00-14:00\"\n", at(9, 13, 30), at(9, 14, 0)},
+		{"other timezone", "22:00-08:00", "    timezone: \"Europe/Berlin\"\n", at(9, 17, 0), at(10, 2, 0)},
+		{"other timezone outside window", "22:00-08:00", "    timezone: \"Europe/Berlin\"\n", at(9, 12, 0), time.Time{}},
+	}
+
+	for _, test := range tests {
+		clock := align.NewFakeClock(test.now)
+
+		config := withSettings(managerTestConfig, "  quiet_hours: \""+test.group+"\"\n") + test.person
+		manager := createClockTestManager(t, config, clock)
+
+		discord := newFakeDiscord()
+		align.InitDiscord(manager, discord, "app")
+
+		manager.OnCompletion()
+
+		if test.expected.IsZero() {
+			require.Len(discord.Messages("dm-1"), 1, test.name)
+			continue
+		}
+
+		clock.Set(test.expected.Add(-time.Minute))
+		require.Empty(discord.Messages("dm-1"), test.name)
+
+		clock.Set(test.expected)
+		require.Len(discord.Messages("dm-1"), 1, test.name)
+	}
+}
+
+// Persons are contacted at the contact time on their own clock, or at their own contact time
+func TestPersonContact(t *testing.T) {
+	require := require.New(t)
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.Nil(err)
+
+	clock := align.NewFakeClock(time.Date(2024, time.January, 7, 10, 0, 0, 0, loc))
+
+	manager := createClockTestManager(t, managerTestConfig+`  - name: "Person 2"
+    request_method: "discord"
+    response_method: "discord"
+    id: "2"
+    timezone: "America/Los_Angeles"
+  - name: "Person 3"
+    request_method: "discord"
+    response_method: "discord"
+    id: "3"
+    contact_time: "0 18 * * 0"
+`, clock)
+
+	discord := newFakeDiscord()
+	align.InitDiscord(manager, discord, "app")
+
+	// Only persons on the group's clock are contacted right away
+	manager.OnContact()
+	require.Len(discord.Messages("dm-1"), 2)
+	require.Empty(discord.Messages("dm-2"))
+	require.Empty(discord.Messages("dm-3"))
+
+	clock.Set(time.Date(2024, time.January, 7, 12, 59, 0, 0, loc))
+	require.Empty(discord.Messages("dm-2"))
+
+	clock.Set(time.Date(2024, time.January, 7, 13, 0, 0, 0, loc))
+	require.Len(discord.Messages("dm-2"), 2)
+	require.Empty(discord.Messages("dm-3"))
+
+	clock.Set(time.Date(2024, time.January, 7, 18, 0, 0, 0, loc))
+	require.Len(discord.Messages("dm-3"), 2)
+	require.Empty(manager.Held)
+
+	// Everyone is asked about the same dates
+	require.Contains(discord.Messages("dm-1")[1].Content, "Tuesday 01/09")
+	require.Equal(discord.Messages("dm-1")[1].Content, discord.Messages("dm-2")[1].Content)
+	require.Equal(discord.Messages("dm-1")[1].Content, discord.Messages("dm-3")[1].Content)
+}
+
+// Held requests are cancelled when a new schedule starts, and are never held past the deadline
+func TestHeldRequests(t *testing.T) {
+	require := require.New(t)
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.Nil(err)
+
+	// The request is held overnight
+	clock := align.NewFakeClock(time.Date(2024, time.January, 9, 23, 0, 0, 0, loc))
+
+	manager := createClockTestManager(t, managerTestConfig+"    quiet_hours: \"22:00-08:00\"\n", clock)
+
+	discord := newFakeDiscord()
+	align.InitDiscord(manager, discord, "app")
+
+	manager.OnContact()
+	require.Empty(discord.Messages("dm-1"))
+	require.Len(manager.Held, 1)
+	require.Equal("request", manager.Held[0].Kind)
+
+	// A new schedule replaces the held request with its own
+	clock.Set(time.Date(2024, time.January, 10, 7, 0, 0, 0, loc))
+	manager.OnContact()
+	require.Len(manager.Held, 1)
+
+	clock.Set(time.Date(2024, time.January, 10, 8, 0, 0, 0, loc))
+	require.Len(discord.Messages("dm-1"), 2)
+	require.Empty(manager.Held)
+
+	// Requests that would only arrive after the Monday deadline are sent during quiet hours instead
+	clock = align.NewFakeClock(time.Date(2024, time.January, 15, 9, 30, 0, 0, loc))
+
+	manager = createClockTestManager(t, managerTestConfig+"    quiet_hours: \"09:00-12:00\"\n", clock)
+
+	discord = newFakeDiscord()
+	align.InitDiscord(manager, discord, "app")
+
+	manager.OnContact()
+	require.Len(discord.Messages("dm-1"), 2)
+	require.Empty(manager.Held)
+}
+
+// The status command is answered right away, and the status is sent once everyone's answers have been read
+func TestDiscordStatus(t *testing.T) {
+	require := require.New(t)
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.Nil(err)
+
+	clock := align.NewFakeClock(time.Date(2024, time.January, 7, 10, 0, 0, 0, loc))
+	manager := createClockTestManager(t, harnessTestConfig, clock)
+
+	discord := newFakeDiscord()
+	align.InitDiscord(manager, discord, "app")
+
+	manager.OnContact()
+	discord.React(discord.Messages("dm-1")[1].ID, "2️⃣", "1")
+
+	discord.Command("1", "guild", "status")
+
+	responses := discord.Responses()
+	require.Len(responses, 1)
+	require.Equal(discordgo.InteractionResponseDeferredChannelMessageWithSource, responses[0].Type)
+	require.Equal(discordgo.MessageFlagsEphemeral, responses[0].Data.Flags)
+
+	messages := discord.Messages("guild")
+	require.Len(messages, 1)
+	require.Contains(messages[0].Content, "✅ Wednesday 01/10")
+	require.Contains(messages[0].Content, "Person 2")
+}
+
+// Names and titles are shown as they are written, whatever formatting characters they contain
+func TestEscaping(t *testing.T) {
+	require := require.New(t)
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.Nil(err)
+
+	long := strings.Repeat("x", 1100)
+	config := `
+settings:
+  title: "<b>&"
+  interval: 7
+  offset: 2
+  timezone: "America/New_York"
+  contact_time: "0 10 * * 0"
+  deadline_time: "0 10 * * 1"
+  templates:
+    header: "{{ index .Unknowns 0 }}"
+
+persons:
+  - name: "*_~|"
+    request_method: "discord"
+    response_method: "discord"
+    id: "1"
+  - name: "` + long + `"
+    request_method: "discord"
+    response_method: "discord"
+    id: "2"
+  - name: "<b>&"
+    request_method: "telegram_keyboard"
+    response_method: "telegram"
+    id: "3"
+`
+
+	clock := align.NewFakeClock(time.Date(2024, time.January, 7, 10, 0, 0, 0, loc))
+	manager := createClockTestManager(t, config, clock)
+
+	discord := newFakeDiscord()
+	align.InitDiscord(manager, discord, "app")
+
+	telegram := newFakeTelegram(t)
+	align.InitTelegram(manager, telegram)
+
+	// The header template fails when it is run, so the default message is used with only the title escaped
+	manager.OnContact()
+
+	keyboard := telegram.Messages(3)
+	require.Len(keyboard, 1)
+	require.True(strings.HasPrefix(keyboard[0].Text, "<b>Schedule for &lt;b&gt;&amp;</b>"), keyboard[0].Text)
+
+	// Nobody answered, so everyone is listed in the results
+	manager.OnCompletion()
+
+	results := discord.Messages("dm-1")
+	fields := results[len(results)-1].Embeds[0].Fields
+	require.Len(fields, 1)
+	require.NotRegexp(`(^|[^\\])[*_~|]`, fields[0].Value)
+	require.Equal(1024, len([]rune(fields[0].Value)))
+	require.True(strings.HasSuffix(fields[0].Value, "…"))
+
+	messages := telegram.Messages(3)
+	require.Contains(messages[1].Text, "- &lt;b&gt;&amp;\n")
+	require.Contains(messages[1].Text, "- *_~|\n")
+
+	// The output of templates is escaped as a whole
+	manager = createClockTestManager(t, strings.Replace(config, "{{ index .Unknowns 0 }}", "{{ .Title }} < soon", 1), clock)
+
+	telegram = newFakeTelegram(t)
+	align.InitTelegram(manager, telegram)
+
+	manager.OnContact()
+	require.Equal("&lt;b&gt;&amp; &lt; soon", telegram.Messages(3)[0].Text)
+}
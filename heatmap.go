@@ -0,0 +1,228 @@
+package align
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/* ---- GLOBALS ---- */
+
+// Sizes of the heatmap, in pixels
+const (
+	heatmapCell    = 24 // The width and height of a single cell
+	heatmapScale   = 2  // How many pixels wide a single pixel of a glyph is
+	heatmapPadding = 8  // Space around the grid and its labels
+	heatmapNameLen = 12 // How many characters of a person's name are shown
+)
+
+// Colors of the heatmap
+var (
+	heatmapBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	heatmapText       = color.RGBA{0x2b, 0x2d, 0x31, 0xff}
+	heatmapYes        = color.RGBA{0x57, 0xf2, 0x87, 0xff} // Available
+	heatmapMaybe      = color.RGBA{0xfe, 0xe7, 0x5c, 0xff} // Might be available
+	heatmapNo         = color.RGBA{0xed, 0x42, 0x45, 0xff} // Not available
+	heatmapUnknown    = color.RGBA{0xd9, 0xdb, 0xde, 0xff} // Didn't answer or opted out
+	heatmapBest       = color.RGBA{0x58, 0x65, 0xf2, 0xff} // Outlines the best days
+)
+
+// A small 3x5 pixel font for the heatmap's labels, as the standard library can't draw text. Every row of a glyph is
+// three bits, with the leftmost pixel as the highest bit
+var heatmapGlyphs = map[rune][5]uint8{
+	'A': {0b010, 0b101, 0b111, 0b101, 0b101},
+	'B': {0b110, 0b101, 0b110, 0b101, 0b110},
+	'C': {0b011, 0b100, 0b100, 0b100, 0b011},
+	'D': {0b110, 0b101, 0b101, 0b101, 0b110},
+	'E': {0b111, 0b100, 0b110, 0b100, 0b111},
+	'F': {0b111, 0b100, 0b110, 0b100, 0b100},
+	'G': {0b011, 0b100, 0b101, 0b101, 0b011},
+	'H': {0b101, 0b101, 0b111, 0b101, 0b101},
+	'I': {0b111, 0b010, 0b010, 0b010, 0b111},
+	'J': {0b001, 0b001, 0b001, 0b101, 0b010},
+	'K': {0b101, 0b101, 0b110, 0b101, 0b101},
+	'L': {0b100, 0b100, 0b100, 0b100, 0b111},
+	'M': {0b101, 0b111, 0b111, 0b101, 0b101},
+	'N': {0b110, 0b101, 0b101, 0b101, 0b101},
+	'O': {0b010, 0b101, 0b101, 0b101, 0b010},
+	'P': {0b110, 0b101, 0b110, 0b100, 0b100},
+	'Q': {0b010, 0b101, 0b101, 0b110, 0b011},
+	'R': {0b110, 0b101, 0b110, 0b101, 0b101},
+	'S': {0b011, 0b100, 0b010, 0b001, 0b110},
+	'T': {0b111, 0b010, 0b010, 0b010, 0b010},
+	'U': {0b101, 0b101, 0b101, 0b101, 0b111},
+	'V': {0b101, 0b101, 0b101, 0b101, 0b010},
+	'W': {0b101, 0b101, 0b111, 0b111, 0b101},
+	'X': {0b101, 0b101, 0b010, 0b101, 0b101},
+	'Y': {0b101, 0b101, 0b010, 0b010, 0b010},
+	'Z': {0b111, 0b001, 0b010, 0b100, 0b111},
+	'0': {0b111, 0b101, 0b101, 0b101, 0b111},
+	'1': {0b010, 0b110, 0b010, 0b010, 0b111},
+	'2': {0b110, 0b001, 0b010, 0b100, 0b111},
+	'3': {0b110, 0b001, 0b010, 0b001, 0b110},
+	'4': {0b101, 0b101, 0b111, 0b001, 0b001},
+	'5': {0b111, 0b100, 0b110, 0b001, 0b110},
+	'6': {0b011, 0b100, 0b111, 0b101, 0b111},
+	'7': {0b111, 0b001, 0b010, 0b010, 0b010},
+	'8': {0b111, 0b101, 0b111, 0b101, 0b111},
+	'9': {0b111, 0b101, 0b111, 0b001, 0b110},
+	'-': {0b000, 0b000, 0b111, 0b000, 0b000},
+	'.': {0b000, 0b000, 0b000, 0b000, 0b010},
+}
+
+/* ---- FUNCTIONS ---- */
+
+// Render a heatmap of everyone's availability as a PNG, with a row for every person and a column for every date. The
+// best days are outlined. Must be called while holding the manager's lock
+func (m *Manager) renderHeatmap(days []day) ([]byte, error) {
+	dates := m.generateDates()
+	if len(dates) == 0 || len(m.config.Persons) == 0 {
+		return nil, nil
+	}
+
+	best := map[string]bool{}
+	for _, d := range days {
+		best[d.Timestamp] = true
+	}
+
+	// Size the image around the longest name and the two rows of date labels
+	labels := [][]rune{}
+	nameLen := 0
+	for i, person := range m.config.Persons {
+		label := heatmapLabel(person.Name, i)
+		if len(label) > nameLen {
+			nameLen = len(label)
+		}
+
+		labels = append(labels, label)
+	}
+	if nameLen > heatmapNameLen {
+		nameLen = heatmapNameLen
+	}
+
+	lineHeight := 6 * heatmapScale
+	left := 2*heatmapPadding + nameLen*4*heatmapScale
+	top := 2*heatmapPadding + 2*lineHeight
+	width := left + len(dates)*heatmapCell + heatmapPadding
+	height := top + len(m.config.Persons)*heatmapCell + heatmapPadding
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{heatmapBackground}, image.Point{}, draw.Src)
+
+	for j, date := range dates {
+		x := left + j*heatmapCell
+		timestamp := date.Format(DATE_FORMAT)
+
+		// Outline the best days
+		if best[timestamp] {
+			heatmapFill(img, image.Rect(x-2, top-heatmapPadding/2, x+heatmapCell+2, top+len(m.config.Persons)*heatmapCell+2), heatmapBest)
+			heatmapFill(img, image.Rect(x, top-heatmapPadding/2+2, x+heatmapCell, top+len(m.config.Persons)*heatmapCell), heatmapBackground)
+		}
+
+		// Label the column with the first letter of the weekday and the day of the month
+		weekday := []rune(m.catalog().Weekdays[date.Weekday()])
+		heatmapDrawText(img, x+heatmapCell/2-2*heatmapScale, heatmapPadding, string(weekday[:1]), heatmapText)
+		heatmapDrawText(img, x+heatmapCell/2-4*heatmapScale, heatmapPadding+lineHeight, date.Format("02"), heatmapText)
+
+		for i, person := range m.config.Persons {
+			y := top + i*heatmapCell
+
+			c := heatmapUnknown
+			if availability, ok := m.availability[person.Name]; ok {
+				switch {
+				case availability[timestamp]:
+					c = heatmapYes
+				case m.tentative[person.Name][timestamp]:
+					c = heatmapMaybe
+				default:
+					c = heatmapNo
+				}
+			}
+
+			heatmapFill(img, image.Rect(x+1, y+1, x+heatmapCell-1, y+heatmapCell-1), c)
+		}
+	}
+
+	// Label the rows with the persons' names
+	for i, label := range labels {
+		if len(label) > nameLen {
+			label = label[:nameLen]
+		}
+
+		heatmapDrawText(img, heatmapPadding, top+i*heatmapCell+(heatmapCell-5*heatmapScale)/2, string(label), heatmapText)
+	}
+
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// Get the label of a person's row. The font only has letters without accents and digits, so other characters are left
+// out. Names without any characters the font has, such as names in other scripts, are labelled with the number of
+// their row instead
+func heatmapLabel(name string, row int) []rune {
+	label := []rune{}
+	drawable := false
+	for _, r := range strings.ToUpper(name) {
+		if _, ok := heatmapGlyphs[r]; ok {
+			label = append(label, r)
+			drawable = true
+		} else if unicode.IsSpace(r) {
+			label = append(label, ' ')
+		}
+	}
+
+	if !drawable {
+		return []rune(strconv.Itoa(row + 1))
+	}
+
+	return []rune(strings.TrimSpace(string(label)))
+}
+
+// Fill a rectangle of an image with a color
+func heatmapFill(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, &image.Uniform{c}, image.Point{}, draw.Src)
+}
+
+// Draw text with the heatmap's font, with its top left corner at the given point. Characters the font doesn't have
+// are left blank
+func heatmapDrawText(img draw.Image, x int, y int, text string, c color.Color) {
+	for _, r := range strings.ToUpper(text) {
+		glyph, ok := heatmapGlyphs[r]
+		if ok {
+			for row, bits := range glyph {
+				for col := 0; col < 3; col++ {
+					if bits&(0b100>>col) == 0 {
+						continue
+					}
+
+					px := x + col*heatmapScale
+					py := y + row*heatmapScale
+					heatmapFill(img, image.Rect(px, py, px+heatmapScale, py+heatmapScale), c)
+				}
+			}
+		}
+
+		x += 4 * heatmapScale
+	}
+}
+
+// Heatmap returns a PNG of everyone's availability in the last completion. Returns false if there is none
+func (m *Manager) Heatmap() ([]byte, bool) {
+	m.edit.Lock()
+	defer m.edit.Unlock()
+
+	if m.LastResult == nil || m.LastResult.Heatmap == nil {
+		return nil, false
+	}
+
+	return m.LastResult.Heatmap, true
+}
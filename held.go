@@ -0,0 +1,265 @@
+package align
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+/* ---- TYPES ---- */
+
+// heldMessage is a message held back until it is due, such as a request sent at a person's own contact time or a
+// result held back during their quiet hours. Held messages are stored with the manager, so they are still sent if
+// align restarts
+type heldMessage struct {
+	Kind   string    // The kind of message, such as 'request' or 'reminder'
+	Person string    // The name of the person the message is for
+	At     time.Time // When the message is sent
+
+	entry cron.EntryID // The clock entry sending the message
+}
+
+/* ---- GLOBALS ---- */
+
+// Kinds of messages that can be held back
+const (
+	heldRequest      = "request"
+	heldApproval     = "approval"
+	heldResponse     = "response"
+	heldAnnouncement = "announcement"
+	heldReminder     = "reminder"
+)
+
+/* ---- FUNCTIONS ---- */
+
+// Send a message to a person at the given time, or right away if it has already passed. Messages that would be sent
+// during the person's quiet hours are held back until the quiet hours end, except for requests that would then only
+// arrive after the deadline
+func (m *Manager) deliver(person Person, kind string, at time.Time) {
+	requested := at
+	if end, quiet := m.quietUntil(person, at); quiet {
+		at = end
+	}
+
+	// A request is no use once the deadline has passed, so it is sent during quiet hours (or right away) instead
+	if kind == heldRequest {
+		if deadline, err := m.nextDeadline(m.clock.Now()); err == nil && !at.Before(deadline) {
+			log.Printf("[WARN]: request for '%v' would arrive after the deadline, sending it early\n", person.Name)
+
+			at = requested
+			if !at.Before(deadline) {
+				at = m.clock.Now()
+			}
+		}
+	}
+
+	if !at.After(m.clock.Now()) {
+		m.send(person, kind)
+		return
+	}
+
+	log.Printf("[INFO]: holding %v for '%v' until %v\n", kind, person.Name, at)
+
+	m.edit.Lock()
+	m.hold(&heldMessage{Kind: kind, Person: person.Name, At: at})
+	m.edit.Unlock()
+
+	m.saveHeld()
+}
+
+// Schedule a held message on the manager's clock. The manager's lock must be held
+func (m *Manager) hold(message *heldMessage) {
+	message.entry = m.clock.Schedule(onceSchedule{at: message.At}, cron.FuncJob(func() {
+		m.release(message)
+	}))
+	m.Held = append(m.Held, message)
+}
+
+// Send a held message once it is due, unless it has been cancelled since
+func (m *Manager) release(message *heldMessage) {
+	m.edit.Lock()
+	held := false
+	for i, h := range m.Held {
+		if h == message {
+			m.Held = append(m.Held[:i], m.Held[i+1:]...)
+			held = true
+			break
+		}
+	}
+	m.edit.Unlock()
+
+	if !held {
+		return
+	}
+
+	m.saveHeld()
+
+	if person, ok := m.person(message.Person); ok {
+		m.send(person, message.Kind)
+	}
+}
+
+// Cancel the held messages of the last schedule when a new one starts. Announcements and reminders are about the
+// decided event rather than the schedule, so they are still sent
+func (m *Manager) cancelHeld() {
+	m.edit.Lock()
+	defer m.edit.Unlock()
+
+	held := []*heldMessage{}
+	for _, message := range m.Held {
+		if message.Kind == heldAnnouncement || message.Kind == heldReminder {
+			held = append(held, message)
+			continue
+		}
+
+		log.Printf("[INFO]: cancelling %v held for '%v'\n", message.Kind, message.Person)
+		m.clock.Remove(message.entry)
+	}
+	m.Held = held
+}
+
+// Schedule the held messages stored with the manager, replacing any scheduled before. Messages that were due while
+// align wasn't running are sent once the module they are sent with has been initialized
+func (m *Manager) scheduleHeld() {
+	m.edit.Lock()
+
+	now := m.clock.Now()
+	stored := m.Held
+	m.Held = nil
+
+	due := []*heldMessage{}
+	for _, message := range stored {
+		m.clock.Remove(message.entry)
+
+		// Messages for persons who have left the group are dropped
+		person, ok := m.person(message.Person)
+		if !ok {
+			continue
+		}
+
+		if message.At.After(now) {
+			m.hold(message)
+			continue
+		}
+
+		// Requests are dropped once the deadline they were for has passed
+		if deadline, err := m.nextDeadline(message.At); message.Kind == heldRequest && err == nil && !deadline.After(now) {
+			continue
+		}
+
+		method := person.ResponseMethod
+		if message.Kind == heldRequest {
+			method = person.RequestMethod
+		}
+
+		// Messages stay held until they can be sent
+		if !m.initialized(method) {
+			message.entry = 0
+			m.Held = append(m.Held, message)
+			continue
+		}
+
+		due = append(due, message)
+	}
+	m.edit.Unlock()
+
+	if len(due) > 0 {
+		m.saveHeld()
+	}
+
+	for _, message := range due {
+		person, _ := m.person(message.Person)
+		go m.send(person, message.Kind)
+	}
+}
+
+// Save the held messages with SQL
+func (m *Manager) saveHeld() {
+	if !m.options.UseSQL {
+		return
+	}
+
+	if err := m.db.Save(m).Error; err != nil {
+		log.Printf("[ERR]: error saving held messages to SQL (err: %v)\n", err)
+	}
+}
+
+// Send a message of the given kind to a person using their method for it
+func (m *Manager) send(person Person, kind string) {
+	if kind == heldRequest {
+		m.request(person)
+		return
+	}
+
+	m.edit.Lock()
+	r, decision := m.LastResult, m.Decision
+	m.edit.Unlock()
+
+	var err error
+	switch kind {
+	case heldApproval, heldResponse:
+		methods := responses
+		if kind == heldApproval {
+			methods = approvals
+		}
+
+		method, ok := methods[person.ResponseMethod]
+		if !ok {
+			log.Printf("[ERR]: %v method '%v' does not exist for person '%v'\n", kind, person.ResponseMethod, person.Name)
+			return
+		}
+
+		if r == nil {
+			return
+		}
+		err = method(person, m, r.Days, r.Unknowns, r.Available)
+
+	case heldAnnouncement, heldReminder:
+		methods := announcements
+		if kind == heldReminder {
+			methods = reminders
+		}
+
+		method, ok := methods[person.ResponseMethod]
+		if !ok {
+			log.Printf("[ERR]: %v method '%v' does not exist for person '%v'\n", kind, person.ResponseMethod, person.Name)
+			return
+		}
+
+		if decision == nil {
+			return
+		}
+		err = method(person, m, *decision)
+
+	default:
+		log.Printf("[ERR]: cannot send unknown message kind '%v'\n", kind)
+		return
+	}
+
+	if err != nil {
+		log.Printf("[ERR]: error sending %v (err: %v)\n", kind, err)
+	} else {
+		log.Printf("[INFO]: %v method '%v' completed for person '%v'\n", kind, person.ResponseMethod, person.Name)
+	}
+}
+
+// Check whether the module a method belongs to, such as 'discord' for 'discord_channel', has been initialized
+func (m *Manager) initialized(method string) bool {
+	module, _, _ := strings.Cut(method, "_")
+	_, ok := m.moduleConfigs[module]
+
+	return ok
+}
+
+// Find the person with the given name
+func (m *Manager) person(name string) (Person, bool) {
+	for _, person := range m.config.Persons {
+		if person.Name == name {
+			return person, true
+		}
+	}
+
+	return Person{}, false
+}
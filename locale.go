@@ -0,0 +1,144 @@
+package align
+
+import (
+	"embed"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+/* ---- TYPES ---- */
+
+// locale represents a translation catalog for the messages persons are sent
+type locale struct {
+	Name       string            `yaml:"name"`        // The name of the language
+	DateFormat string            `yaml:"date_format"` // How dates are formatted, where 'Monday' is the weekday name
+	Weekdays   []string          `yaml:"weekdays"`    // The names of the weekdays, starting on Sunday
+	Messages   map[string]string `yaml:"messages"`    // Message formats by key
+}
+
+/* ---- GLOBALS ---- */
+
+// The locale used when no locale is set in the config
+const DEFAULT_LOCALE = "en"
+
+// The translation catalogs, one YAML file per locale
+//
+//go:embed locales/*.yml
+var localeFiles embed.FS
+
+// The catalog of the default locale, which every other catalog is checked against
+var defaultLocale *locale
+
+/* ---- FUNCTIONS ---- */
+
+// Load the default catalog when align starts
+func init() {
+	defaultLocale = mustLoadLocale(DEFAULT_LOCALE)
+}
+
+// Get the names of every locale align has a catalog for
+func Locales() []string {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		return nil
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yml"))
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Load the catalog of a locale, such as 'de' or 'fr'
+func loadLocale(name string) (*locale, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = DEFAULT_LOCALE
+	}
+
+	data, err := localeFiles.ReadFile("locales/" + name + ".yml")
+	if err != nil {
+		return nil, fmt.Errorf("unknown locale '%v' (available: %v)", name, strings.Join(Locales(), ", "))
+	}
+
+	l := locale{}
+	if err := yaml.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("cannot read locale '%v' (err: %v)", name, err)
+	}
+
+	if l.DateFormat == "" || len(l.Weekdays) != 7 {
+		return nil, fmt.Errorf("locale '%v' needs a date format and seven weekdays", name)
+	}
+
+	// Every message has to be translated, and take the same values as the default
+	if defaultLocale != nil {
+		for key, format := range defaultLocale.Messages {
+			translated, ok := l.Messages[key]
+			if !ok {
+				return nil, fmt.Errorf("locale '%v' is missing message '%v'", name, key)
+			}
+
+			if strings.Count(translated, "%v") != strings.Count(format, "%v") {
+				return nil, fmt.Errorf("locale '%v' message '%v' takes %v values instead of %v", name, key, strings.Count(translated, "%v"), strings.Count(format, "%v"))
+			}
+		}
+	}
+
+	return &l, nil
+}
+
+// Load a catalog embedded in align, which can't fail unless the catalog itself is broken
+func mustLoadLocale(name string) *locale {
+	l, err := loadLocale(name)
+	if err != nil {
+		log.Fatalf("[ERR]: cannot load embedded locale (err: %v)\n", err)
+	}
+
+	return l
+}
+
+// Get the catalog of the manager's locale
+func (m *Manager) catalog() *locale {
+	if m.locale == nil {
+		return defaultLocale
+	}
+
+	return m.locale
+}
+
+// Get a message in the manager's locale, formatted with the given values
+func (m *Manager) text(key string, values ...interface{}) string {
+	format, ok := m.catalog().Messages[key]
+	if !ok {
+		log.Printf("[WARN]: message '%v' does not exist\n", key)
+		return key
+	}
+
+	if len(values) == 0 {
+		return format
+	}
+
+	return fmt.Sprintf(format, values...)
+}
+
+// FormatDate formats a date the way it is shown to persons in the manager's locale
+func (m *Manager) FormatDate(date time.Time) string {
+	l := m.catalog()
+
+	// Go always formats weekdays in English, so the name is swapped for the translated one
+	formatted := date.Format(l.DateFormat)
+	return strings.Replace(formatted, date.Weekday().String(), l.Weekdays[date.Weekday()], 1)
+}
+
+// Format a date and a time of day in the manager's locale
+func (m *Manager) formatDateTime(t time.Time) string {
+	return m.text("date_at_time", m.FormatDate(t), t.Format(EVENT_TIME_FORMAT))
+}
@@ -2,10 +2,13 @@ package align
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	mysql_driver "github.com/go-sql-driver/mysql"
@@ -16,22 +19,55 @@ import (
 )
 
 // Constant for a day's duration
+//
+// Deprecated: days aren't always 24 hours long when clocks change for daylight saving time, so dates are generated
+// with calendar arithmetic instead
 const DAY_DURATION = int(time.Hour * 24)
 
-// How the days will be formatted
+// How the days are shown to persons in the default English locale. Dates are kept and given to Decide as ISO dates
+// (DATE_FORMAT) instead, whatever the locale
 const TIME_FORMAT = "Monday 01/02"
 
+// result is used to encapsulate the outcome of a completion
+type result struct {
+	Days      []day    // Days the most people are available
+	Unknowns  []string // Persons who didn't respond
+	Skipped   []string // Persons who opted out
+	Available int      // How many people are available on the days
+	Heatmap   []byte   // PNG of everyone's availability
+}
+
 // Manager struct represents a top level manager class
 type Manager struct {
 	gorm.Model
 	Name       string       `gorm:"uniqueIndex,length:256"` // The name identifier for the manager
 	ContactDay sql.NullTime // The day persons are contacted
 
-	availability  map[string]map[string]bool `gorm:"-"` // Persons' availabilities
-	config        *Config                    `gorm:"-"` // Base align config
-	moduleConfigs map[string]interface{}     `gorm:"-"` // configs for "modules"
-	loc           *time.Location             `gorm:"-"` // Timezone location for cron
-	options       *Options                   `gorm:"-"` // Manager options
+	Decision      *Event          `gorm:"serializer:json"` // The event the group decided on
+	Confirmations map[string]bool `gorm:"serializer:json"` // Whether persons are going to the decided event
+	Reminded      []string        `gorm:"serializer:json"` // The reminders already sent for the decided event
+	Awaiting      bool            // Whether results are held back until organizers pick the final day
+	LastResult    *result         `gorm:"serializer:json"` // The result of the last completion
+	Held          []*heldMessage  `gorm:"serializer:json"` // Messages held back until they are due
+
+	availability  map[string]map[string]bool    `gorm:"-"` // Persons' availabilities
+	tentative     map[string]map[string]bool    `gorm:"-"` // Persons' tentative (maybe) availabilities
+	responded     map[string]bool               `gorm:"-"` // Persons who have answered their request
+	skipped       map[string]bool               `gorm:"-"` // Persons who opted out of the current schedule
+	defaults      map[string]map[string]bool    `gorm:"-"` // Persons' availabilities according to their calendars
+	config        *Config                       `gorm:"-"` // Base align config
+	moduleConfigs map[string]interface{}        `gorm:"-"` // configs for "modules"
+	loc           *time.Location                `gorm:"-"` // Timezone location for cron
+	locations     map[string]*time.Location     `gorm:"-"` // Timezone locations of persons who live in their own timezone
+	weekdays      map[time.Weekday]bool         `gorm:"-"` // The weekdays persons are asked about
+	excluded      []dateRange                   `gorm:"-"` // The dates persons are never asked about
+	window        dateWindow                    `gorm:"-"` // The window of dates persons are asked about
+	locale        *locale                       `gorm:"-"` // The catalog messages are translated with
+	templates     map[string]*template.Template `gorm:"-"` // The group's templates replacing default messages
+	options       *Options                      `gorm:"-"` // Manager options
+
+	clock           Clock          `gorm:"-"` // Clock the manager's jobs run on
+	reminderEntries []cron.EntryID `gorm:"-"` // Cron entries of the scheduled reminders
 
 	edit *sync.Mutex `gorm:"-"` // Mutex for accessing manager fields
 	db   *gorm.DB    `gorm:"-"` // Database for persistance of records
@@ -42,31 +78,59 @@ func (m *Manager) OnContact() {
 	log.Printf("[INFO]: starting contact\n")
 
 	// Update the contact day
-	now := time.Now().In(m.loc)
+	now := m.clock.Now().In(m.loc)
 	m.ContactDay.Time = now
 	m.ContactDay.Valid = true
 
-	if err := m.db.Save(m).Error; err != nil {
-		log.Printf("[ERR]: error saving contact day to SQL, stopping (err: %v)\n", err)
-		return
+	// Messages held back for the last schedule are no longer sent
+	m.cancelHeld()
+
+	if m.options.UseSQL {
+		if err := m.db.Save(m).Error; err != nil {
+			log.Printf("[ERR]: error saving contact day to SQL, stopping (err: %v)\n", err)
+			return
+		}
 	}
 
+	// Reset answers from the last schedule
+	m.edit.Lock()
+	m.tentative = make(map[string]map[string]bool)
+	m.responded = make(map[string]bool)
+	m.skipped = make(map[string]bool)
+	m.defaults = make(map[string]map[string]bool)
+	m.edit.Unlock()
+
 	// For each person
 	for _, person := range m.config.Persons {
-		log.Printf("[INFO]: starting contact for '%v'\n", person.Name)
-
-		// Find the person's request method
-		request, ok := requests[person.RequestMethod]
-		if !ok {
-			log.Printf("[ERR]: request method '%v' does not exist for person '%v'\n", person.RequestMethod, person.Name)
+		// Persons in their own timezone are contacted at the contact time on their own clock
+		at, err := m.contactTime(person, now)
+		if err != nil {
+			log.Printf("[ERR]: cannot find contact time for '%v', contacting now (err: %v)\n", person.Name, err)
 		}
 
-		// Perform the response
-		if err := request(person, m); err != nil {
-			log.Printf("[ERR]: error sending request (err: %v)\n", err)
-		} else {
-			log.Printf("[INFO]: request method '%v' completed for person '%v'\n", person.RequestMethod, person.Name)
-		}
+		m.deliver(person, heldRequest, at)
+	}
+}
+
+// Request a person's availability using their request method
+func (m *Manager) request(person Person) {
+	log.Printf("[INFO]: starting contact for '%v'\n", person.Name)
+
+	// Pre-fill the person's availability from their calendar
+	m.loadDefaults(person)
+
+	// Find the person's request method
+	request, ok := requests[person.RequestMethod]
+	if !ok {
+		log.Printf("[ERR]: request method '%v' does not exist for person '%v'\n", person.RequestMethod, person.Name)
+		return
+	}
+
+	// Perform the response
+	if err := request(person, m); err != nil {
+		log.Printf("[ERR]: error sending request (err: %v)\n", err)
+	} else {
+		log.Printf("[INFO]: request method '%v' completed for person '%v'\n", person.RequestMethod, person.Name)
 	}
 }
 
@@ -80,6 +144,7 @@ func (m *Manager) OnCompletion() {
 		gather, ok := gathers[person.RequestMethod]
 		if !ok {
 			log.Printf("[ERR]: gather method '%v' does not exist for person '%v'\n", person.RequestMethod, person.Name)
+			continue
 		}
 
 		// Gather information for the person
@@ -98,6 +163,35 @@ func (m *Manager) OnCompletion() {
 		}
 	}
 
+	// Persons who didn't answer are assumed to be free whenever their calendar is free
+	for _, person := range m.config.Persons {
+		defaults := m.loadDefaults(person)
+
+		m.edit.Lock()
+		if defaults != nil && !m.responded[person.Name] {
+			log.Printf("[INFO]: using calendar availability for '%v'\n", person.Name)
+			m.availability[person.Name] = copyAvailability(defaults)
+		}
+		m.edit.Unlock()
+	}
+
+	// Remove persons who opted out of this schedule
+	m.edit.Lock()
+	skipped := []string{}
+	for _, person := range m.config.Persons {
+		if m.skipped[person.Name] {
+			delete(m.availability, person.Name)
+			delete(m.tentative, person.Name)
+			skipped = append(skipped, person.Name)
+		}
+	}
+	m.edit.Unlock()
+
+	log.Println("[INFO]: found skipped users")
+	for _, name := range skipped {
+		log.Printf("[INFO]: - %v\n", name)
+	}
+
 	// Filter schedules that are all false
 	unknowns := []string{}
 	for k, schedule := range m.availability {
@@ -110,6 +204,11 @@ func (m *Manager) OnCompletion() {
 			}
 		}
 
+		// Persons who only answered maybe still responded
+		for _, tentative := range m.tentative[k] {
+			hasTrue = hasTrue || tentative
+		}
+
 		// Remove schedules that don't have at least one true entry or are nil
 		if !hasTrue || schedule == nil {
 			delete(m.availability, k)
@@ -125,49 +224,385 @@ func (m *Manager) OnCompletion() {
 	// Everyone has sent in an availability schedule, so calculate available days
 	var n int
 	var days []day
-	for n = len(m.config.Persons) - len(unknowns); n > 0; n-- {
-		days = align(m.availability, n)
+	for n = len(m.config.Persons) - len(unknowns) - len(skipped); n > 0; n-- {
+		days = align(m.availability, m.tentative, n)
 
 		if len(days) > 0 {
 			break
 		}
 	}
 
+	// Attach dates to the available days and order them chronologically
+	dates := map[string]time.Time{}
+	for _, date := range m.generateDates() {
+		dates[date.Format(DATE_FORMAT)] = date
+	}
+	for i := range days {
+		days[i].Date = dates[days[i].Timestamp]
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date.Before(days[j].Date)
+	})
+
 	log.Println("[INFO]: calculated available days")
 	for _, day := range days {
 		log.Printf("[INFO]: - %v (with persons %v)\n", day.Timestamp, strings.Join(day.AvailablePersons, ", "))
 	}
 
-	// Send out available days to all persons
+	// Keep the result for persons who ask for it later, along with a heatmap of everyone's availability
+	m.edit.Lock()
+	heatmap, err := m.renderHeatmap(days)
+	if err != nil {
+		log.Printf("[ERR]: cannot render heatmap (err: %v)\n", err)
+	}
+
+	m.LastResult = &result{
+		Days:      days,
+		Unknowns:  unknowns,
+		Skipped:   skipped,
+		Available: n,
+		Heatmap:   heatmap,
+	}
+	m.edit.Unlock()
+
+	// If the group has organizers, results are held back until they pick the final day
+	organizers := m.organizers()
+	if len(organizers) == 0 {
+		// Publish the best day as an event
+		if len(days) > 0 {
+			m.publish(m.newEvent(days[0]))
+		}
+
+		m.respond()
+	} else {
+		m.edit.Lock()
+		m.Awaiting = true
+		m.edit.Unlock()
+	}
+
+	// Keep the result, so it can still be sent out and picked from if align restarts
+	if m.options.UseSQL {
+		if err := m.db.Save(m).Error; err != nil {
+			log.Printf("[ERR]: error saving result to SQL (err: %v)\n", err)
+		}
+	}
+
+	// Ask organizers to pick the final day
+	for _, person := range organizers {
+		m.deliver(person, heldApproval, m.clock.Now())
+	}
+
+	// Lock in the best day and ask persons to confirm
+	if m.config.Confirm && m.config.AutoPick && len(organizers) == 0 && len(days) > 0 {
+		if err := m.Decide(days[0].Timestamp); err != nil {
+			log.Printf("[ERR]: error deciding on best day (err: %v)\n", err)
+		}
+	}
+
+	log.Println("[INFO]: completion was successful")
+}
+
+// Check whether a timestamp is a date of the current schedule
+func (m *Manager) scheduled(timestamp string) bool {
+	for _, date := range m.generateTimestamps() {
+		if date == timestamp {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Send out the available days of the last completion to all persons
+func (m *Manager) respond() {
 	for _, person := range m.config.Persons {
-		// Find the person's response method
-		response, ok := responses[person.ResponseMethod]
-		if !ok {
-			log.Printf("[ERR]: response method '%v' does not exist for person '%v'\n", person.ResponseMethod, person.Name)
+		m.deliver(person, heldResponse, m.clock.Now())
+	}
+}
+
+// Decide locks in the event for a date of the current schedule, given as an ISO date such as '2024-01-06', and
+// announces it to every person
+func (m *Manager) Decide(timestamp string) error {
+	return m.decide(timestamp, false)
+}
+
+// Check whether results are held back until an organizer picks the final day
+func (m *Manager) awaitingPick() bool {
+	m.edit.Lock()
+	defer m.edit.Unlock()
+
+	return m.Awaiting
+}
+
+// Lock in the event for a date. Organizers pick from results held back for them, so a pick is refused once the
+// final day has been picked
+func (m *Manager) decide(timestamp string, pick bool) error {
+	log.Printf("[INFO]: deciding on '%v'\n", timestamp)
+
+	// Find the date being decided on
+	var date *time.Time
+	for _, d := range m.generateDates() {
+		if d.Format(DATE_FORMAT) == timestamp {
+			date = &d
+			break
+		}
+	}
+
+	if date == nil {
+		return fmt.Errorf("'%v' is not a date of the current schedule", timestamp)
+	}
+
+	// Persons who were available on the date are expected to attend
+	chosen := day{Timestamp: timestamp, Date: *date, AvailablePersons: []string{}}
+
+	m.edit.Lock()
+	if pick && !m.Awaiting {
+		m.edit.Unlock()
+		return fmt.Errorf("the final day has already been picked")
+	}
+
+	if m.LastResult != nil {
+		for _, d := range m.LastResult.Days {
+			if d.Timestamp == timestamp {
+				chosen.AvailablePersons = d.AvailablePersons
+			}
 		}
+	}
 
-		// Perform the response
-		if err := response(person, m, days, unknowns, n); err != nil {
-			log.Printf("[ERR]: error sending response (err: %v)\n", err)
+	event := m.newEvent(chosen)
+	m.Decision = &event
+	m.Confirmations = make(map[string]bool)
+	m.Reminded = []string{}
+
+	// Results held back for organizers are sent out along with their decision
+	r := m.LastResult
+	awaiting := m.Awaiting
+	m.Awaiting = false
+	m.edit.Unlock()
+
+	if awaiting && r != nil {
+		m.respond()
+	}
+
+	if m.options.UseSQL {
+		if err := m.db.Save(m).Error; err != nil {
+			log.Printf("[ERR]: error saving decision to SQL (err: %v)\n", err)
+		}
+	}
+
+	m.publish(event)
+	m.scheduleReminders()
+
+	// Tell every person about the decision, asking whether they are going if confirmations are enabled
+	for _, person := range m.config.Persons {
+		m.deliver(person, heldAnnouncement, m.clock.Now())
+	}
+
+	return nil
+}
+
+// Schedule the reminders of the decided event and the held messages, sending the ones missed while align wasn't
+// running once the modules they are sent with are initialized
+func (m *Manager) resume() {
+	m.scheduleReminders()
+	m.scheduleHeld()
+}
+
+// Publish an event to every module that has been initialized
+func (m *Manager) publish(event Event) {
+	for name, publish := range publishers {
+		// Only publish to modules that have been initialized
+		if _, ok := m.moduleConfigs[name]; !ok {
+			continue
+		}
+
+		if err := publish(m, event); err != nil {
+			log.Printf("[ERR]: error publishing event to '%v' (err: %v)\n", name, err)
 		} else {
-			log.Printf("[INFO]: response method '%v' completed for person '%v'\n", person.RequestMethod, person.Name)
+			log.Printf("[INFO]: published event to '%v'\n", name)
 		}
 	}
+}
 
-	log.Println("[INFO]: completion was successful")
+// Record whether a person is going to the decided event
+func (m *Manager) confirm(name string, going bool) error {
+	m.edit.Lock()
+
+	// Replies are accepted until the day of the event is over
+	if m.Decision == nil || m.clock.Now().After(m.Decision.Date.AddDate(0, 0, 1)) {
+		m.edit.Unlock()
+		return fmt.Errorf("there is no upcoming event")
+	}
+
+	if m.Confirmations == nil {
+		m.Confirmations = make(map[string]bool)
+	}
+	m.Confirmations[name] = going
+	m.edit.Unlock()
+
+	log.Printf("[INFO]: '%v' replied to the event (going: %v)\n", name, going)
+
+	if m.options.UseSQL {
+		if err := m.db.Save(m).Error; err != nil {
+			log.Printf("[ERR]: error saving confirmation to SQL (err: %v)\n", err)
+		}
+	}
+
+	return nil
+}
+
+// Get the persons allowed to pick the final day
+func (m *Manager) organizers() []Person {
+	organizers := []Person{}
+	for _, person := range m.config.Persons {
+		if person.Organizer {
+			organizers = append(organizers, person)
+		}
+	}
+
+	return organizers
+}
+
+// Get the names of persons going, not going and who haven't replied to the decided event
+func (m *Manager) confirmations() ([]string, []string, []string) {
+	m.edit.Lock()
+	defer m.edit.Unlock()
+
+	going, notGoing, pending := []string{}, []string{}, []string{}
+	for _, person := range m.config.Persons {
+		confirmed, ok := m.Confirmations[person.Name]
+		switch {
+		case !ok:
+			pending = append(pending, person.Name)
+		case confirmed:
+			going = append(going, person.Name)
+		default:
+			notGoing = append(notGoing, person.Name)
+		}
+	}
+
+	return going, notGoing, pending
+}
+
+// Get a person's current answer without closing their request. Returns the person's availability, tentative
+// availability and whether they have answered yet
+func (m *Manager) current(person Person) (map[string]bool, map[string]bool, bool) {
+	// Some methods can only be read from the platform they were sent on
+	if peek, ok := peeks[person.RequestMethod]; ok {
+		availability, answered, err := peek(person, m)
+		if err == nil {
+			m.edit.Lock()
+			defer m.edit.Unlock()
+
+			return availability, copyAvailability(m.tentative[person.Name]), answered || m.responded[person.Name]
+		}
+
+		log.Printf("[ERR]: error reading current answer for '%v' (err: %v)\n", person.Name, err)
+	}
+
+	m.edit.Lock()
+	defer m.edit.Unlock()
+
+	return copyAvailability(m.availability[person.Name]), copyAvailability(m.tentative[person.Name]), m.responded[person.Name]
+}
+
+// Get the names of persons who haven't answered or opted out of the current schedule
+func (m *Manager) pending() []string {
+	names := []string{}
+	for _, person := range m.config.Persons {
+		_, _, answered := m.current(person)
+
+		m.edit.Lock()
+		skipped := m.skipped[person.Name]
+		m.edit.Unlock()
+
+		if !answered && !skipped {
+			names = append(names, person.Name)
+		}
+	}
+
+	return names
+}
+
+// Load a person's default availability from their calendar, reading it if it hasn't been read this schedule.
+// Returns nil if the person has no calendar or it can't be read
+func (m *Manager) loadDefaults(person Person) map[string]bool {
+	m.edit.Lock()
+	defaults, ok := m.defaults[person.Name]
+	m.edit.Unlock()
+
+	if ok {
+		return defaults
+	}
+
+	defaults, err := m.calendarDefaults(person)
+	if err != nil {
+		log.Printf("[ERR]: error reading calendar for '%v' (err: %v)\n", person.Name, err)
+	}
+
+	m.edit.Lock()
+	m.defaults[person.Name] = defaults
+	m.edit.Unlock()
+
+	return defaults
+}
+
+// Mark that a person has answered their request
+func (m *Manager) markResponded(name string) {
+	m.edit.Lock()
+	m.responded[name] = true
+	m.edit.Unlock()
+}
+
+// Opt a person out of the current schedule
+func (m *Manager) skip(name string) {
+	log.Printf("[INFO]: '%v' opted out of the current schedule\n", name)
+
+	m.edit.Lock()
+	m.skipped[name] = true
+	m.edit.Unlock()
+}
+
+// Generate the dates persons are asked about for the current contact day
+func (m *Manager) generateDates() []time.Time {
+	dates := []time.Time{}
+	for _, date := range m.windowDates() {
+		// Skip dates the group doesn't meet on
+		if m.candidate(date) {
+			dates = append(dates, date)
+		}
+	}
+
+	return dates
+}
+
+// Generate the timestamps persons are asked about for the current contact day. Timestamps are ISO dates, so answers
+// don't depend on the locale, and are only formatted with formatTimestamp when they are shown to persons
+func (m *Manager) generateTimestamps() []string {
+	timestamps := []string{}
+	for _, date := range m.generateDates() {
+		timestamps = append(timestamps, date.Format(DATE_FORMAT))
+	}
+
+	return timestamps
+}
+
+// Format a timestamp the way it is shown to persons in the manager's locale
+func (m *Manager) formatTimestamp(timestamp string) string {
+	date, err := time.ParseInLocation(DATE_FORMAT, timestamp, m.loc)
+	if err != nil {
+		return timestamp
+	}
+
+	return m.FormatDate(date)
 }
 
 // Generate a base availabiltiy map
 func (m *Manager) generateAvailability() map[string]bool {
 	availability := map[string]bool{}
 
-	// Get the current date
-	year, month, day := m.ContactDay.Time.Date()
-	today := time.Date(year, month, day, 0, 0, 0, 0, m.loc)
-
-	for day := m.config.Offset; day < m.config.Interval+m.config.Offset; day++ {
-		// Generate the timestamp and set the availability to false
-		timestamp := today.Add(time.Duration(DAY_DURATION * day)).Format(TIME_FORMAT)
+	// Set the availability of each timestamp to false
+	for _, timestamp := range m.generateTimestamps() {
 		availability[timestamp] = false
 	}
 
@@ -222,11 +657,9 @@ func CreateManager(name string, path string, options Options) (*Manager, error)
 
 		log.Println("[INFO]: migrating gorm databases")
 
-		// Migrate the manager database
-		if !db.Migrator().HasTable(&Manager{}) {
-			if err = db.AutoMigrate(&Manager{}); err != nil {
-				return nil, err
-			}
+		// Migrate the manager database, adding any columns that are missing
+		if err = db.AutoMigrate(&Manager{}); err != nil {
+			return nil, err
 		}
 
 		log.Println("[INFO]: loading managers from SQL")
@@ -258,6 +691,10 @@ func CreateManager(name string, path string, options Options) (*Manager, error)
 
 	// Populate manager fields
 	manager.availability = make(map[string]map[string]bool)
+	manager.tentative = make(map[string]map[string]bool)
+	manager.responded = make(map[string]bool)
+	manager.skipped = make(map[string]bool)
+	manager.defaults = make(map[string]map[string]bool)
 	manager.moduleConfigs = make(map[string]interface{})
 	manager.config = &config
 	manager.edit = &sync.Mutex{}
@@ -273,29 +710,93 @@ func CreateManager(name string, path string, options Options) (*Manager, error)
 	manager.loc = loc
 
 	log.Println("[INFO]: successfully loaded timezone")
-	log.Println("[INFO]: starting cron service")
 
-	// Start the cron service
-	cronService := cron.New(cron.WithLocation(loc))
-	cronService.Start()
+	// Tell time with the given clock, or with a cron service on the system clock
+	manager.clock = options.Clock
+	if manager.clock == nil {
+		log.Println("[INFO]: starting cron service")
+		manager.clock = newCronClock(loc)
+	}
+
+	// Load the catalog messages are translated with
+	l, err := loadLocale(config.Locale)
+	if err != nil {
+		return nil, err
+	}
+	manager.locale = l
+
+	// Load the timezones of persons
+	if err := manager.loadPersons(); err != nil {
+		return nil, err
+	}
+
+	// Load the dates persons can be asked about
+	if err := manager.loadCandidates(); err != nil {
+		return nil, err
+	}
+
+	// Load the templates replacing default messages
+	if err := manager.loadTemplates(); err != nil {
+		return nil, err
+	}
+
+	// Check the event settings before anything is scheduled with them
+	if config.EventTime != "" {
+		if _, err := time.Parse(EVENT_TIME_FORMAT, config.EventTime); err != nil {
+			return nil, fmt.Errorf("invalid event time '%v' (err: %v)", config.EventTime, err)
+		}
+	}
+	for _, reminder := range config.Reminders {
+		if _, err := parseReminder(reminder); err != nil {
+			return nil, err
+		}
+	}
 
 	log.Println("[INFO]: adding 'ContactTime' cron func")
 
 	// Send availability requests according to the contact time cron string
-	_, err = cronService.AddFunc(manager.config.ContactTime, manager.OnContact)
+	contact, err := parseSpec(manager.config.ContactTime, loc)
 	if err != nil {
 		return nil, err
 	}
+	manager.clock.Schedule(contact, cron.FuncJob(manager.OnContact))
 
 	log.Println("[INFO]: adding 'OnCompletion' cron func")
 
 	// Create a job that will align schedules at a given deadline
-	_, err = cronService.AddFunc(manager.config.DeadlineTime, manager.OnCompletion)
+	deadline, err := parseSpec(manager.config.DeadlineTime, loc)
 	if err != nil {
 		return nil, err
 	}
+	manager.clock.Schedule(deadline, cron.FuncJob(manager.OnCompletion))
+
+	// Reschedule the reminders and held messages that were lost when align stopped
+	manager.resume()
 
 	log.Println("[INFO]: returning newly created manager")
 
 	return &manager, nil
 }
+
+// Copy an availability map so it can be read without holding the manager's lock
+func copyAvailability(availability map[string]bool) map[string]bool {
+	if availability == nil {
+		return nil
+	}
+
+	c := make(map[string]bool, len(availability))
+	for date, available := range availability {
+		c[date] = available
+	}
+
+	return c
+}
+
+// Join names into a list, or 'nobody' if there are none
+func (m *Manager) listNames(names []string) string {
+	if len(names) == 0 {
+		return m.text("nobody")
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,552 @@
+package align_test
+
+import (
+	"bytes"
+	"database/sql"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ethanbaker/align"
+	"github.com/stretchr/testify/require"
+)
+
+const managerTestConfig = `
+settings:
+  title: "Group Meetup"
+  interval: 7
+  offset: 2
+  timezone: "America/New_York"
+  contact_time: "0 10 * * 0"
+  deadline_time: "0 10 * * 1"
+  confirm: true
+
+persons:
+  - name: "Person 1"
+    request_method: "discord"
+    response_method: "discord"
+    id: "1"
+`
+
+// Create a manager without SQL from a config string
+func createTestManager(t *testing.T, config string) *align.Manager {
+	return createClockTestManager(t, config, nil)
+}
+
+// Create a manager without SQL from a config string, telling time with the given clock
+func createClockTestManager(t *testing.T, config string, clock align.Clock) *align.Manager {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.Nil(t, os.WriteFile(path, []byte(config), 0o600))
+
+	manager, err := align.CreateManager("test-manager", path, align.Options{
+		UseSQL: false,
+		Clock:  clock,
+	})
+	require.Nil(t, err)
+
+	return manager
+}
+
+// Require that a manager can't be created from a config string
+func requireConfigError(t *testing.T, config string) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.Nil(t, os.WriteFile(path, []byte(config), 0o600))
+
+	_, err := align.CreateManager("test-manager", path, align.Options{UseSQL: false})
+	require.NotNil(t, err, config)
+}
+
+// Contact persons on Sunday 01/07/2024, so they are asked about the dates from Tuesday 01/09/2024
+func setContactDay(manager *align.Manager) {
+	manager.ContactDay = sql.NullTime{Time: time.Date(2024, time.January, 7, 10, 0, 0, 0, time.UTC), Valid: true}
+}
+
+// Add settings to the end of a test config's settings
+func withSettings(config string, settings string) string {
+	return strings.Replace(config, "\npersons:", settings+"\npersons:", 1)
+}
+
+func TestTimestamps(t *testing.T) {
+	require := require.New(t)
+
+	manager := createTestManager(t, managerTestConfig)
+	setContactDay(manager)
+
+	// Persons are asked about every day of the interval, starting after the offset
+	timestamps := manager.Timestamps()
+	require.Len(timestamps, 7)
+	require.Equal("2024-01-09", timestamps[0])
+	require.Equal("2024-01-15", timestamps[6])
+}
+
+func TestAlignTentative(t *testing.T) {
+	require := require.New(t)
+
+	availability := map[string]map[string]bool{
+		"Person 1": {"2024-01-09": true, "2024-01-10": true},
+		"Person 2": {"2024-01-09": true, "2024-01-10": false},
+	}
+	tentative := map[string]map[string]bool{
+		"Person 2": {"2024-01-09": false, "2024-01-10": true},
+	}
+
+	// Tentative persons are listed on the days that have enough available persons, but not counted
+	days := align.Align(availability, tentative, 2)
+	require.Len(days, 1)
+	require.Equal("2024-01-09", days[0].Timestamp)
+	require.Empty(days[0].TentativePersons)
+
+	days = align.Align(availability, tentative, 1)
+	require.Len(days, 2)
+	for _, day := range days {
+		if day.Timestamp == "2024-01-10" {
+			require.Equal([]string{"Person 1"}, day.AvailablePersons)
+			require.Equal([]string{"Person 2"}, day.TentativePersons)
+		}
+	}
+}
+
+func TestDecide(t *testing.T) {
+	require := require.New(t)
+
+	manager := createTestManager(t, managerTestConfig)
+	setContactDay(manager)
+
+	// Only dates of the current schedule can be decided on
+	require.NotNil(manager.Decide("2024-01-08"))
+	require.Nil(manager.Decision)
+
+	// Deciding locks in the event and resets confirmations
+	require.Nil(manager.Decide("2024-01-09"))
+	require.NotNil(manager.Decision)
+	require.Equal("Group Meetup", manager.Decision.Title)
+	require.Equal("2024-01-09", manager.Decision.Date.Format("2006-01-02"))
+	require.Empty(manager.Confirmations)
+}
+
+func TestPick(t *testing.T) {
+	require := require.New(t)
+
+	manager := createTestManager(t, managerTestConfig)
+	setContactDay(manager)
+
+	// Organizers can only pick while results are held back for them
+	require.NotNil(manager.Pick("2024-01-10"))
+	require.Nil(manager.Decision)
+
+	manager.Awaiting = true
+	require.Nil(manager.Pick("2024-01-10"))
+	require.False(manager.Awaiting)
+	require.Equal("2024-01-10", manager.Decision.Date.Format("2006-01-02"))
+
+	// Only the first pick counts
+	require.NotNil(manager.Pick("2024-01-11"))
+	require.Equal("2024-01-10", manager.Decision.Date.Format("2006-01-02"))
+
+	// Deciding directly still overrides the pick
+	require.Nil(manager.Decide("2024-01-11"))
+	require.Equal("2024-01-11", manager.Decision.Date.Format("2006-01-02"))
+}
+
+func TestReminderConfig(t *testing.T) {
+	// Reminders can be written out or given as durations
+	createTestManager(t, withSettings(managerTestConfig, `  event_time: "18:30"
+  reminders: ["1 day before", "2 hours before", "90m"]
+`))
+
+	// Invalid reminders and event times are rejected when the config is loaded
+	for _, settings := range []string{"  reminders: [\"soon\"]\n", "  event_time: \"6pm\"\n"} {
+		requireConfigError(t, withSettings(managerTestConfig, settings))
+	}
+}
+
+func TestCandidateDates(t *testing.T) {
+	require := require.New(t)
+
+	manager := createTestManager(t, withSettings(managerTestConfig, `  weekdays: ["Fri", "saturday"]
+  exclude: ["2024-01-13", "2024-01-19..2024-01-20"]
+`))
+
+	// Only Fridays and Saturdays that aren't excluded are asked about
+	setContactDay(manager)
+	require.Equal([]string{"2024-01-12"}, manager.Timestamps())
+
+	manager.ContactDay = sql.NullTime{Time: time.Date(2024, time.January, 14, 10, 0, 0, 0, time.UTC), Valid: true}
+	require.Empty(manager.Timestamps())
+
+	manager.ContactDay = sql.NullTime{Time: time.Date(2024, time.January, 21, 10, 0, 0, 0, time.UTC), Valid: true}
+	require.Equal([]string{"2024-01-26", "2024-01-27"}, manager.Timestamps())
+
+	// Invalid weekdays are rejected when the config is loaded
+	requireConfigError(t, withSettings(managerTestConfig, "  weekdays: [\"Fr\"]\n"))
+}
+
+func TestHolidays(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"holidays.yml": `
+- name: "Founders Day"
+  date: "2024-01-10"
+- name: "Winter Break"
+  date: "2024-01-12..2024-01-13"
+`,
+		"holidays.ics": "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY:Founders Day\r\nTRANSP:TRANSPARENT\r\nDTSTART;VALUE=DATE:20240110\r\nEND:VEVENT\r\n" +
+			"BEGIN:VEVENT\r\nSUMMARY:Winter Break\r\nDTSTART;VALUE=DATE:20240112\r\nDTEND;VALUE=DATE:20240114\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+	}
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		require.Nil(os.WriteFile(path, []byte(data), 0o600))
+
+		manager := createTestManager(t, withSettings(managerTestConfig, "  holidays: \""+path+"\"\n"))
+		setContactDay(manager)
+
+		// Holidays are never asked about, even if they are marked as free
+		require.Equal([]string{"2024-01-09", "2024-01-11", "2024-01-14", "2024-01-15"}, manager.Timestamps(), name)
+	}
+}
+
+func TestPersonTimezone(t *testing.T) {
+	// Persons can live in their own timezone
+	createTestManager(t, managerTestConfig+`    timezone: "Europe/Berlin"
+`)
+
+	// Invalid timezones are rejected when the config is loaded
+	requireConfigError(t, managerTestConfig+"    timezone: \"Mars/Olympus\"\n")
+}
+
+func TestContactTime(t *testing.T) {
+	require := require.New(t)
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.Nil(err)
+
+	manager := createTestManager(t, managerTestConfig+`  - name: "Person 2"
+    request_method: "discord"
+    response_method: "discord"
+    id: "2"
+    timezone: "America/Los_Angeles"
+  - name: "Person 3"
+    request_method: "discord"
+    response_method: "discord"
+    id: "3"
+    timezone: "Europe/Berlin"
+  - name: "Person 4"
+    request_method: "discord"
+    response_method: "discord"
+    id: "4"
+    contact_time: "0 18 * * 1"
+  - name: "Person 5"
+    request_method: "discord"
+    response_method: "discord"
+    id: "5"
+    timezone: "Europe/Berlin"
+    contact_time: "30 9 * * 1"
+`)
+
+	// The schedule starts at the group's contact time on Sunday. Persons behind the group are contacted later, persons
+	// ahead of it right away as their contact time has passed, and persons with their own contact time at that time on
+	// their own clock
+	start := time.Date(2024, time.January, 7, 10, 0, 0, 0, loc)
+
+	tests := []struct {
+		name     string
+		expected time.Time
+	}{
+		{"Person 1", start},
+		{"Person 2", time.Date(2024, time.January, 7, 13, 0, 0, 0, loc)},
+		{"Person 3", start},
+		{"Person 4", time.Date(2024, time.January, 8, 18, 0, 0, 0, loc)},
+		{"Person 5", time.Date(2024, time.January, 8, 3, 30, 0, 0, loc)},
+	}
+
+	for _, test := range tests {
+		at, err := manager.ContactTime(test.name, start)
+		require.Nil(err, test.name)
+		require.True(test.expected.Equal(at), "%v: %v", test.name, at.In(loc))
+	}
+}
+
+func TestEventWhen(t *testing.T) {
+	require := require.New(t)
+
+	persons := `  - name: "Person 2"
+    request_method: "discord"
+    response_method: "discord"
+    id: "2"
+    timezone: "America/Los_Angeles"
+  - name: "Person 3"
+    request_method: "discord"
+    response_method: "discord"
+    id: "3"
+    timezone: "Europe/Berlin"
+`
+
+	tests := []struct {
+		name     string
+		date     time.Time
+		expected string
+	}{
+		{"Person 1", time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC), "Wednesday 01/10 at 18:30"},
+		{"Person 2", time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC), "Wednesday 01/10 at 15:30"},
+		{"Person 3", time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC), "Thursday 01/11 at 00:30"},
+
+		// New York has moved its clocks forward but Berlin hasn't yet
+		{"Person 3", time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC), "Sunday 03/10 at 23:30"},
+	}
+
+	// Events are shown at the event time on every person's own clock
+	manager := createTestManager(t, withSettings(managerTestConfig, "  event_time: \"18:30\"\n")+persons)
+	for _, test := range tests {
+		require.Equal(test.expected, manager.EventWhen(align.Event{Date: test.date}, test.name), test.name)
+	}
+
+	// Without an event time, only the date is shown
+	manager = createTestManager(t, managerTestConfig+persons)
+	require.Equal("Wednesday 01/10", manager.EventWhen(align.Event{Date: time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC)}, "Person 3"))
+}
+
+func TestContactConfig(t *testing.T) {
+	// Persons can have their own contact time and quiet hours
+	createTestManager(t, withSettings(managerTestConfig, "  quiet_hours: \"22:00-08:00\"\n")+`    contact_time: "0 18 * * 1"
+    quiet_hours: "13:00-14:00"
+`)
+
+	// Invalid contact times and quiet hours are rejected when the config is loaded
+	for _, config := range []string{
+		managerTestConfig + "    contact_time: \"every monday\"\n",
+		managerTestConfig + "    quiet_hours: \"22:00\"\n",
+		withSettings(managerTestConfig, "  quiet_hours: \"08:00-08:00\"\n"),
+	} {
+		requireConfigError(t, config)
+	}
+}
+
+func TestRange(t *testing.T) {
+	require := require.New(t)
+
+	tests := []struct {
+		expr        string
+		first, last string
+		days        int
+	}{
+		{"next calendar week Mon–Sun", "2024-01-08", "2024-01-14", 7},
+		{"rest of this month", "2024-01-08", "2024-01-31", 24},
+		{"next month", "2024-02-01", "2024-02-29", 29},
+		{"from +2d for 10d", "2024-01-09", "2024-01-18", 10},
+		{"from +1w for 1w", "2024-01-14", "2024-01-20", 7},
+	}
+
+	for _, test := range tests {
+		manager := createTestManager(t, withSettings(managerTestConfig, "  range: \""+test.expr+"\"\n"))
+		setContactDay(manager)
+
+		// Every day from the first to the last day of the range is asked about
+		timestamps := manager.Timestamps()
+		require.Len(timestamps, test.days, test.expr)
+		require.Equal(test.first, timestamps[0], test.expr)
+		require.Equal(test.last, timestamps[len(timestamps)-1], test.expr)
+	}
+
+	// Invalid ranges are rejected when the config is loaded
+	requireConfigError(t, withSettings(managerTestConfig, "  range: \"soon\"\n"))
+}
+
+func TestDateGenerationDST(t *testing.T) {
+	require := require.New(t)
+
+	// Weeks where clocks spring forward or fall back, starting on the day persons are contacted
+	tests := []struct {
+		timezone string
+		contact  time.Time
+	}{
+		{"America/New_York", time.Date(2024, time.March, 8, 12, 0, 0, 0, time.UTC)},
+		{"America/New_York", time.Date(2024, time.November, 1, 12, 0, 0, 0, time.UTC)},
+		{"Europe/Berlin", time.Date(2024, time.March, 29, 12, 0, 0, 0, time.UTC)},
+		{"Europe/Berlin", time.Date(2024, time.October, 25, 12, 0, 0, 0, time.UTC)},
+		{"Australia/Sydney", time.Date(2024, time.April, 5, 2, 0, 0, 0, time.UTC)},
+		{"Australia/Sydney", time.Date(2024, time.October, 4, 2, 0, 0, 0, time.UTC)},
+		{"America/Santiago", time.Date(2024, time.September, 6, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		loc, err := time.LoadLocation(test.timezone)
+		require.Nil(err)
+
+		manager := createTestManager(t, strings.Replace(managerTestConfig, "America/New_York", test.timezone, 1))
+		manager.ContactDay = sql.NullTime{Time: test.contact, Valid: true}
+
+		// Every calendar day in the window is asked about exactly once, starting at the first moment of the day
+		dates := manager.Dates()
+		require.Len(dates, 7, test.timezone)
+
+		year, month, day := test.contact.In(loc).Date()
+		for i, date := range dates {
+			expected := time.Date(year, month, day+i+2, 12, 0, 0, 0, loc).Format(align.DATE_FORMAT)
+
+			date = date.In(loc)
+			require.Equal(expected, date.Format(align.DATE_FORMAT), test.timezone)
+			require.NotEqual(date.Day(), date.Add(-time.Minute).Day(), "%v %v", test.timezone, date)
+		}
+	}
+}
+
+func TestLocale(t *testing.T) {
+	require := require.New(t)
+
+	withLocale := func(locale string) string {
+		return withSettings(managerTestConfig, "  locale: \""+locale+"\"\n")
+	}
+
+	// Every catalog is complete
+	require.Contains(align.Locales(), "en")
+	for _, locale := range align.Locales() {
+		createTestManager(t, withLocale(locale))
+	}
+
+	// Dates are shown in the group's language, but always decided on as ISO dates
+	manager := createTestManager(t, withLocale("de"))
+	setContactDay(manager)
+
+	date := time.Date(2024, time.January, 9, 0, 0, 0, 0, time.UTC)
+	require.Equal("Dienstag 09.01.", manager.FormatDate(date))
+	require.NotNil(manager.Decide(manager.FormatDate(date)))
+	require.Nil(manager.Decide(date.Format(align.DATE_FORMAT)))
+	require.Equal("2024-01-09", manager.Decision.Date.Format("2006-01-02"))
+
+	// The default locale is English
+	require.Equal("Tuesday 01/09", createTestManager(t, managerTestConfig).FormatDate(date))
+
+	// Unknown locales are rejected when the config is loaded
+	requireConfigError(t, withLocale("xx"))
+}
+
+func TestTemplates(t *testing.T) {
+	withTemplates := func(templates string) string {
+		return withSettings(managerTestConfig, "  templates:\n"+templates)
+	}
+
+	// Templates can use every value of a message
+	createTestManager(t, withTemplates(`    header: "{{ .Title }} - answer by {{ .Deadline }}"
+    request_body: "{{ range .Dates }}{{ .Emoji }} {{ .Date }}{{ if .Busy }} (busy){{ end }}\n{{ end }}"
+    reminder: "{{ .Title }} is on {{ .Date }} with {{ join .Attendees \", \" }}"
+    result: "{{ .Available }}/{{ .Total }} can make it: {{ range .Dates }}{{ .Date }} {{ end }}"
+    no_result: "No day works. Missing: {{ join .Unknowns \", \" }}"
+`))
+
+	// Broken templates and unknown values are rejected when the config is loaded
+	for _, templates := range []string{"    header: \"{{ .Title \"\n", "    result: \"{{ .Winner }}\"\n", "    reminder: \"{{ nope .Title }}\"\n"} {
+		requireConfigError(t, withTemplates(templates))
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	require := require.New(t)
+
+	manager := createTestManager(t, managerTestConfig)
+	setContactDay(manager)
+
+	// There is no heatmap before the first completion
+	_, ok := manager.Heatmap()
+	require.False(ok)
+
+	manager.OnCompletion()
+
+	data, ok := manager.Heatmap()
+	require.True(ok)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.Nil(err)
+
+	// One row for the person and a column for every date, next to the labels
+	require.Equal(256, img.Bounds().Dx())
+	require.Equal(72, img.Bounds().Dy())
+
+	// The person didn't answer, so their cells are grey
+	r, g, b, _ := img.At(80+12, 40+12).RGBA()
+	require.Equal([]uint32{0xd9, 0xdb, 0xde}, []uint32{r >> 8, g >> 8, b >> 8})
+}
+
+func TestUnknownMethods(t *testing.T) {
+	require := require.New(t)
+
+	config := strings.ReplaceAll(managerTestConfig, `"discord"`, `"carrier_pigeon"`)
+	manager := createTestManager(t, config)
+
+	// Persons whose methods don't exist are skipped instead of stopping the schedule
+	manager.OnContact()
+	require.True(manager.ContactDay.Valid)
+
+	manager.OnCompletion()
+	require.NotNil(manager.LastResult)
+	require.Empty(manager.LastResult.Days)
+}
+
+func TestHeatmapColors(t *testing.T) {
+	require := require.New(t)
+
+	config := strings.Replace(managerTestConfig, `request_method: "discord"`, `request_method: "discord_components"`, 1) + `
+  - name: "Юлия"
+    request_method: "discord"
+    response_method: "discord"
+    id: "2"
+`
+
+	manager := createTestManager(t, config)
+
+	discord := newFakeDiscord()
+	align.InitDiscord(manager, discord, "app")
+
+	manager.OnContact()
+
+	// Person 1 is free on the second date and might be free on the third
+	messages := discord.Messages("dm-1")
+	free := messages[1].Components[0].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	maybe := messages[1].Components[1].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	discord.Select("1", "dm-1", free.CustomID, "1")
+	discord.Select("1", "dm-1", maybe.CustomID, "2")
+
+	manager.OnCompletion()
+
+	data, ok := manager.Heatmap()
+	require.True(ok)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.Nil(err)
+	require.Equal(256, img.Bounds().Dx())
+	require.Equal(96, img.Bounds().Dy())
+
+	colorAt := func(x int, y int) []uint32 {
+		r, g, b, _ := img.At(x, y).RGBA()
+		return []uint32{r >> 8, g >> 8, b >> 8}
+	}
+
+	// Cells are red, green or yellow for the answers, and grey for persons who didn't answer
+	require.Equal([]uint32{0xed, 0x42, 0x45}, colorAt(80+12, 40+12))
+	require.Equal([]uint32{0x57, 0xf2, 0x87}, colorAt(104+12, 40+12))
+	require.Equal([]uint32{0xfe, 0xe7, 0x5c}, colorAt(128+12, 40+12))
+	require.Equal([]uint32{0xd9, 0xdb, 0xde}, colorAt(104+12, 64+12))
+
+	// Only the best day is outlined
+	require.Equal([]uint32{0x58, 0x65, 0xf2}, colorAt(104-1, 40+12))
+	require.Equal([]uint32{0x58, 0x65, 0xf2}, colorAt(128, 40+12))
+	require.Equal([]uint32{0xff, 0xff, 0xff}, colorAt(80, 40+12))
+	require.Equal([]uint32{0xff, 0xff, 0xff}, colorAt(152, 40+12))
+
+	// The font has no Cyrillic letters, so the second row is labelled with its number
+	drawn := 0
+	for x := 0; x < 80; x++ {
+		for y := 64; y < 88; y++ {
+			if colorAt(x, y)[0] == 0x2b {
+				drawn++
+			}
+		}
+	}
+	require.Equal(8*2*2, drawn)
+}
@@ -2,18 +2,69 @@ package align
 
 // All possible request methods
 var requests = map[string]func(Person, *Manager) error{
-	"discord":  DiscordRequest,
-	"telegram": TelegramRequest,
+	"discord":            DiscordRequest,
+	"discord_channel":    DiscordChannelRequest,
+	"discord_components": DiscordComponentsRequest,
+	"telegram":           TelegramRequest,
+	"telegram_group":     TelegramGroupRequest,
+	"telegram_keyboard":  TelegramKeyboardRequest,
 }
 
 // All possible gather methods
 var gathers = map[string]func(Person, *Manager) error{
-	"discord":  DiscordGather,
-	"telegram": TelegramGather,
+	"discord":            DiscordGather,
+	"discord_channel":    DiscordChannelGather,
+	"discord_components": DiscordComponentsGather,
+	"telegram":           TelegramGather,
+	"telegram_group":     TelegramGroupGather,
+	"telegram_keyboard":  TelegramKeyboardGather,
 }
 
 // All possible response methods
 var responses = map[string]func(Person, *Manager, []day, []string, int) error{
-	"discord":  DiscordResponse,
-	"telegram": TelegramResponse,
+	"discord":         DiscordResponse,
+	"discord_channel": DiscordChannelResponse,
+	"telegram":        TelegramResponse,
+	"telegram_group":  TelegramGroupResponse,
+}
+
+// All possible announcement methods, used to tell persons which day was decided on
+var announcements = map[string]func(Person, *Manager, Event) error{
+	"discord":         DiscordAnnounce,
+	"discord_channel": DiscordChannelAnnounce,
+	"telegram":        TelegramAnnounce,
+	"telegram_group":  TelegramGroupAnnounce,
+}
+
+// All possible reminder methods, used to remind attendees of the decided event
+var reminders = map[string]func(Person, *Manager, Event) error{
+	"discord":         DiscordRemind,
+	"discord_channel": DiscordChannelRemind,
+	"telegram":        TelegramRemind,
+	"telegram_group":  TelegramGroupRemind,
+}
+
+// All possible approval methods, used to ask organizers to pick the final day
+var approvals = map[string]func(Person, *Manager, []day, []string, int) error{
+	"discord":         DiscordApprove,
+	"discord_channel": DiscordApprove,
+	"telegram":        TelegramApprove,
+	"telegram_group":  TelegramApprove,
+}
+
+// Methods whose current answers can only be read from the platform they were sent on
+var peeks = map[string]func(Person, *Manager) (map[string]bool, bool, error){
+	"discord":         DiscordPeek,
+	"discord_channel": DiscordChannelPeek,
+}
+
+// All possible calendars to read busy times from
+var calendars = map[string]func(Person, *Manager) ([]busy, bool, error){
+	"ics":    icsBusy,
+	"caldav": caldavBusy,
+}
+
+// All possible places to publish the chosen event to
+var publishers = map[string]func(*Manager, Event) error{
+	"caldav": CalDAVPublish,
 }
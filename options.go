@@ -4,4 +4,7 @@ package align
 type Options struct {
 	// Whether or not align should use an SQL database to persist messages in case of power outages/etc
 	UseSQL bool
+
+	// The clock the manager tells time and runs its jobs with. If nil, the system clock is used
+	Clock Clock
 }
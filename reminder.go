@@ -0,0 +1,207 @@
+package align
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+/* ---- TYPES ---- */
+
+// onceSchedule is a cron schedule that only runs at a single point in time
+type onceSchedule struct {
+	at time.Time
+}
+
+// Next returns the point in time the schedule runs at, or the zero time if it has already passed
+func (s onceSchedule) Next(t time.Time) time.Time {
+	if t.Before(s.at) {
+		return s.at
+	}
+
+	return time.Time{}
+}
+
+/* ---- GLOBALS ---- */
+
+// How the start time of events is formatted in the config
+const EVENT_TIME_FORMAT = "15:04"
+
+// Units reminders can be given in
+var reminderUnits = map[string]time.Duration{
+	"minute": time.Minute,
+	"hour":   time.Hour,
+	"day":    24 * time.Hour,
+	"week":   7 * 24 * time.Hour,
+}
+
+/* ---- FUNCTIONS ---- */
+
+// Parse how long before an event a reminder is sent, such as '1 day before', '2 hours before' or '90m'
+func parseReminder(reminder string) (time.Duration, error) {
+	fields := strings.Fields(strings.TrimSuffix(strings.TrimSpace(reminder), "before"))
+
+	if len(fields) == 2 {
+		n, err := strconv.Atoi(fields[0])
+		unit, ok := reminderUnits[strings.TrimSuffix(strings.ToLower(fields[1]), "s")]
+		if err == nil && ok && n >= 0 {
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	if len(fields) == 1 {
+		if d, err := time.ParseDuration(fields[0]); err == nil && d >= 0 {
+			return d, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid reminder '%v'", reminder)
+}
+
+// Get the time an event starts at in the manager's timezone
+func (m *Manager) eventStart(event Event) time.Time {
+	start, err := time.Parse(EVENT_TIME_FORMAT, m.config.EventTime)
+	if err != nil {
+		start = time.Time{}
+	}
+
+	year, month, day := event.Date.Date()
+	return time.Date(year, month, day, start.Hour(), start.Minute(), 0, 0, m.loc)
+}
+
+// Describe when an event takes place on a person's clock, including its start time if one is set
+func (m *Manager) eventWhen(event Event, person Person) string {
+	if m.config.EventTime == "" {
+		return m.FormatDate(event.Date)
+	}
+
+	return m.formatDateTime(m.eventStart(event).In(m.location(person)))
+}
+
+// Schedule the reminders for the decided event that haven't been sent yet, replacing any scheduled before. Reminders
+// that were missed while align wasn't running are sent right away, or once the modules persons are reminded with have
+// been initialized
+func (m *Manager) scheduleReminders() {
+	m.edit.Lock()
+	defer m.edit.Unlock()
+
+	for _, id := range m.reminderEntries {
+		m.clock.Remove(id)
+	}
+	m.reminderEntries = nil
+
+	if m.Decision == nil {
+		return
+	}
+
+	// Nothing is sent once the event has started
+	now := m.clock.Now()
+	start := m.eventStart(*m.Decision)
+	if !now.Before(start) {
+		return
+	}
+
+	sent := map[string]bool{}
+	for _, reminder := range m.Reminded {
+		sent[reminder] = true
+	}
+
+	for _, reminder := range m.config.Reminders {
+		if sent[reminder] {
+			continue
+		}
+
+		d, err := parseReminder(reminder)
+		if err != nil {
+			log.Printf("[ERR]: cannot schedule reminder (err: %v)\n", err)
+			continue
+		}
+
+		// Capture the reminder for the job, as the loop variable is reused
+		reminder := reminder
+		at := start.Add(-d)
+		if !now.Before(at) {
+			if !m.ready() {
+				log.Printf("[INFO]: holding back missed reminder '%v' until every module is initialized\n", reminder)
+				continue
+			}
+
+			go m.remind(reminder)
+			continue
+		}
+
+		log.Printf("[INFO]: scheduling reminder '%v' for %v\n", reminder, at)
+		m.reminderEntries = append(m.reminderEntries, m.clock.Schedule(onceSchedule{at: at}, cron.FuncJob(func() {
+			m.remind(reminder)
+		})))
+	}
+}
+
+// Check whether the modules persons are reminded with have been initialized. Modules are initialized after the
+// manager is created, so reminders sent before then would be lost
+func (m *Manager) ready() bool {
+	for _, person := range m.config.Persons {
+		// Persons without a reminder method are never reminded
+		if _, ok := reminders[person.ResponseMethod]; !ok {
+			continue
+		}
+
+		if !m.initialized(person.ResponseMethod) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Send a reminder about the decided event to everyone attending it
+func (m *Manager) remind(reminder string) {
+	m.edit.Lock()
+
+	// Make sure the reminder is only sent once
+	if m.Decision == nil {
+		m.edit.Unlock()
+		return
+	}
+	for _, r := range m.Reminded {
+		if r == reminder {
+			m.edit.Unlock()
+			return
+		}
+	}
+	m.Reminded = append(m.Reminded, reminder)
+	event := *m.Decision
+
+	// If persons confirm, only those going are reminded
+	attending := map[string]bool{}
+	if m.config.Confirm {
+		for name, going := range m.Confirmations {
+			attending[name] = going
+		}
+	} else {
+		for _, name := range event.Attendees {
+			attending[name] = true
+		}
+	}
+	m.edit.Unlock()
+
+	log.Printf("[INFO]: sending reminder '%v'\n", reminder)
+
+	if m.options.UseSQL {
+		if err := m.db.Save(m).Error; err != nil {
+			log.Printf("[ERR]: error saving reminder to SQL (err: %v)\n", err)
+		}
+	}
+
+	for _, person := range m.config.Persons {
+		if !attending[person.Name] {
+			continue
+		}
+
+		m.deliver(person, heldReminder, m.clock.Now())
+	}
+}
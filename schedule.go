@@ -0,0 +1,321 @@
+package align
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+/* ---- TYPES ---- */
+
+// dateRange represents an inclusive range of days
+type dateRange struct {
+	Name  string    // Why the days are excluded, such as the name of a holiday
+	Start time.Time // The first day of the range
+	End   time.Time // The last day of the range
+}
+
+// dateWindow finds the first date persons are asked about and how many days they are asked about, given noon on the
+// day a schedule started
+type dateWindow func(today time.Time) (time.Time, int)
+
+// holiday represents an entry of a YAML holiday file
+type holiday struct {
+	Name string `yaml:"name"` // The name of the holiday
+	Date string `yaml:"date"` // The date or range of dates of the holiday
+}
+
+/* ---- GLOBALS ---- */
+
+// How dates are formatted in the config
+const DATE_FORMAT = "2006-01-02"
+
+// Separates the first and last day of a date range in the config
+const dateRangeSeparator = ".."
+
+// Matches windows written as an offset and a length, such as 'from +2d for 10d'
+var windowPattern = regexp.MustCompile(`^from \+?(\d+)([dw]) for (\d+)([dw])$`)
+
+/* ---- FUNCTIONS ---- */
+
+// Parse a weekday from its full or abbreviated name, such as 'Friday' or 'fri'
+func parseWeekday(name string) (time.Weekday, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || (len(name) >= 3 && strings.HasPrefix(full, name)) {
+			return day, nil
+		}
+	}
+
+	return time.Sunday, fmt.Errorf("invalid weekday '%v'", name)
+}
+
+// Parse a single date, such as '2024-12-25', or a range of dates, such as '2024-12-24..2024-12-31'
+func parseDateRange(value string, loc *time.Location) (dateRange, error) {
+	first, last, isRange := strings.Cut(strings.TrimSpace(value), dateRangeSeparator)
+
+	start, err := time.ParseInLocation(DATE_FORMAT, strings.TrimSpace(first), loc)
+	if err != nil {
+		return dateRange{}, fmt.Errorf("invalid date '%v' (err: %v)", value, err)
+	}
+
+	end := start
+	if isRange {
+		end, err = time.ParseInLocation(DATE_FORMAT, strings.TrimSpace(last), loc)
+		if err != nil {
+			return dateRange{}, fmt.Errorf("invalid date '%v' (err: %v)", value, err)
+		}
+	}
+
+	if end.Before(start) {
+		return dateRange{}, fmt.Errorf("date range '%v' ends before it starts", value)
+	}
+
+	return dateRange{Start: start, End: end}, nil
+}
+
+// Parse the window of dates persons are asked about, such as 'next week', 'rest of this month' or 'from +2d for 10d'
+func parseWindow(expr string) (dateWindow, error) {
+	normalized := strings.ToLower(strings.Join(strings.Fields(expr), " "))
+
+	// Weeks always run from Monday to Sunday
+	normalized = strings.TrimSpace(strings.NewReplacer("mon-sun", "", "mon–sun", "", "calendar ", "").Replace(normalized))
+
+	switch normalized {
+	case "next week":
+		return func(today time.Time) (time.Time, int) {
+			daysToMonday := (8 - int(today.Weekday())) % 7
+			if daysToMonday == 0 {
+				daysToMonday = 7
+			}
+
+			return today.AddDate(0, 0, daysToMonday), 7
+		}, nil
+
+	case "rest of this week", "this week":
+		return func(today time.Time) (time.Time, int) {
+			return today.AddDate(0, 0, 1), (7 - int(today.Weekday())) % 7
+		}, nil
+
+	case "rest of this month", "this month":
+		return func(today time.Time) (time.Time, int) {
+			last := time.Date(today.Year(), today.Month()+1, 0, 12, 0, 0, 0, today.Location())
+			return today.AddDate(0, 0, 1), last.Day() - today.Day()
+		}, nil
+
+	case "next month":
+		return func(today time.Time) (time.Time, int) {
+			first := time.Date(today.Year(), today.Month()+1, 1, 12, 0, 0, 0, today.Location())
+			last := time.Date(today.Year(), today.Month()+2, 0, 12, 0, 0, 0, today.Location())
+			return first, last.Day()
+		}, nil
+	}
+
+	match := windowPattern.FindStringSubmatch(normalized)
+	if match == nil {
+		return nil, fmt.Errorf("invalid range '%v'", expr)
+	}
+
+	offset, _ := strconv.Atoi(match[1])
+	if match[2] == "w" {
+		offset *= 7
+	}
+
+	length, _ := strconv.Atoi(match[3])
+	if match[4] == "w" {
+		length *= 7
+	}
+
+	return func(today time.Time) (time.Time, int) {
+		return today.AddDate(0, 0, offset), length
+	}, nil
+}
+
+// Get every date in the window of the current schedule, before weekdays and excluded dates are removed. Dates are
+// found with calendar arithmetic, so days aren't skipped or repeated when clocks change for daylight saving time
+func (m *Manager) windowDates() []time.Time {
+	// Days are counted from noon, which exists on every day in every timezone
+	year, month, day := m.ContactDay.Time.In(m.loc).Date()
+	today := time.Date(year, month, day, 12, 0, 0, 0, m.loc)
+
+	start, days := m.window(today)
+
+	dates := []time.Time{}
+	for i := 0; i < days; i++ {
+		year, month, day := start.AddDate(0, 0, i).Date()
+		dates = append(dates, startOfDay(year, month, day, m.loc))
+	}
+
+	return dates
+}
+
+// Get the first moment of a day. This is midnight, unless clocks skip midnight for daylight saving time
+func startOfDay(year int, month time.Month, day int, loc *time.Location) time.Time {
+	start := time.Date(year, month, day, 0, 0, 0, 0, loc)
+
+	// Skipped times are moved back to the day before, so move forward until the day starts
+	for start.Day() != day {
+		start = start.Add(time.Minute)
+	}
+
+	return start
+}
+
+// Check whether a date falls in the range
+func (r dateRange) contains(date time.Time) bool {
+	year, month, day := date.Date()
+	d := time.Date(year, month, day, 0, 0, 0, 0, r.Start.Location())
+
+	return !d.Before(r.Start) && !d.After(r.End)
+}
+
+// Load the weekdays and excluded dates persons can be asked about from the config
+func (m *Manager) loadCandidates() error {
+	// Without a range, persons are asked about the days after the offset
+	m.window = func(today time.Time) (time.Time, int) {
+		return today.AddDate(0, 0, m.config.Offset), m.config.Interval
+	}
+
+	if m.config.Range != "" {
+		window, err := parseWindow(m.config.Range)
+		if err != nil {
+			return err
+		}
+
+		m.window = window
+	}
+
+	m.weekdays = map[time.Weekday]bool{}
+	for _, name := range m.config.Weekdays {
+		day, err := parseWeekday(name)
+		if err != nil {
+			return err
+		}
+
+		m.weekdays[day] = true
+	}
+
+	m.excluded = []dateRange{}
+	for _, value := range m.config.Exclude {
+		r, err := parseDateRange(value, m.loc)
+		if err != nil {
+			return err
+		}
+
+		m.excluded = append(m.excluded, r)
+	}
+
+	if m.config.Holidays == "" {
+		return nil
+	}
+
+	holidays, err := readHolidays(m.config.Holidays, m.loc)
+	if err != nil {
+		return fmt.Errorf("cannot read holidays from '%v' (err: %v)", m.config.Holidays, err)
+	}
+	m.excluded = append(m.excluded, holidays...)
+
+	return nil
+}
+
+// Read the holidays in an iCalendar or YAML file
+func readHolidays(path string, loc *time.Location) ([]dateRange, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays := []dateRange{}
+
+	// YAML files list holidays by name and date
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yml" || ext == ".yaml" {
+		entries := []holiday{}
+		if err := yaml.Unmarshal(data, &entries); err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			r, err := parseDateRange(entry.Date, loc)
+			if err != nil {
+				return nil, err
+			}
+
+			r.Name = entry.Name
+			holidays = append(holidays, r)
+		}
+
+		return holidays, nil
+	}
+
+	// Every event in an iCalendar file is a holiday, even if it is marked as free
+	events, err := parseEvents(data, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		year, month, day := event.Start.In(loc).Date()
+		r := dateRange{Name: event.Summary, Start: time.Date(year, month, day, 0, 0, 0, 0, loc)}
+
+		// Events end at the start of the day after their last day
+		r.End = r.Start
+		if event.End.After(event.Start) {
+			year, month, day = event.End.Add(-time.Nanosecond).In(loc).Date()
+			r.End = time.Date(year, month, day, 0, 0, 0, 0, loc)
+		}
+
+		holidays = append(holidays, r)
+	}
+
+	return holidays, nil
+}
+
+// Describe the dates in the current schedule's range that persons weren't asked about because they are excluded
+func (m *Manager) excludedDates() []string {
+	excluded := []string{}
+	for _, date := range m.windowDates() {
+		// Dates on other weekdays would never have been asked about
+		if len(m.weekdays) > 0 && !m.weekdays[date.Weekday()] {
+			continue
+		}
+
+		for _, r := range m.excluded {
+			if !r.contains(date) {
+				continue
+			}
+
+			if r.Name != "" {
+				excluded = append(excluded, fmt.Sprintf("%v (%v)", m.FormatDate(date), r.Name))
+			} else {
+				excluded = append(excluded, m.FormatDate(date))
+			}
+			break
+		}
+	}
+
+	return excluded
+}
+
+// Check whether persons can be asked about a date. Dates must fall on one of the configured weekdays (if any are set)
+// and can't be excluded
+func (m *Manager) candidate(date time.Time) bool {
+	if len(m.weekdays) > 0 && !m.weekdays[date.Weekday()] {
+		return false
+	}
+
+	for _, r := range m.excluded {
+		if r.contains(date) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,353 @@
+package align_test
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	telegram "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/joho/godotenv"
+)
+
+/* ---- DISCORD ---- */
+
+// fakeDiscordMessage represents a message sent or edited on a fake discord session
+type fakeDiscordMessage struct {
+	ID         string                       // The ID of the message
+	ChannelID  string                       // The channel the message was sent to
+	Content    string                       // The text of the message
+	Embeds     []*discordgo.MessageEmbed    // The embeds of the message
+	Components []discordgo.MessageComponent // The rows of components of the message
+	Files      []string                     // The names of the attached files
+}
+
+// fakeDiscord is an in-memory discord session. Messages are recorded instead of sent, and reactions are scripted
+// with React
+type fakeDiscord struct {
+	mu        sync.Mutex
+	count     int
+	messages  []fakeDiscordMessage
+	reactions map[string]map[string][]*discordgo.User // Users who reacted, by message ID and emoji
+	responses []*discordgo.InteractionResponse
+	handlers  []func(*discordgo.Session, *discordgo.InteractionCreate)
+	commands  map[string][]*discordgo.ApplicationCommand // Registered commands, by application ID
+}
+
+// Create a fake discord session
+func newFakeDiscord() *fakeDiscord {
+	return &fakeDiscord{
+		reactions: map[string]map[string][]*discordgo.User{},
+		commands:  map[string][]*discordgo.ApplicationCommand{},
+	}
+}
+
+// Record a message and give it an ID
+func (f *fakeDiscord) record(channelID string, content string, embeds []*discordgo.MessageEmbed, components []discordgo.MessageComponent, files []*discordgo.File) *discordgo.Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.count++
+	message := fakeDiscordMessage{ID: fmt.Sprint(f.count), ChannelID: channelID, Content: content, Embeds: embeds, Components: components}
+	for _, file := range files {
+		message.Files = append(message.Files, file.Name)
+	}
+	f.messages = append(f.messages, message)
+
+	return &discordgo.Message{ID: message.ID, ChannelID: channelID, Content: content, Embeds: embeds}
+}
+
+// Messages returns the messages sent to a channel, in order
+func (f *fakeDiscord) Messages(channelID string) []fakeDiscordMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	messages := []fakeDiscordMessage{}
+	for _, message := range f.messages {
+		if message.ChannelID == channelID {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages
+}
+
+// React adds a user's reaction to a message
+func (f *fakeDiscord) React(messageID string, emoji string, userID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.reactions[messageID] == nil {
+		f.reactions[messageID] = map[string][]*discordgo.User{}
+	}
+	f.reactions[messageID][emoji] = append(f.reactions[messageID][emoji], &discordgo.User{ID: userID})
+}
+
+// Interact sends an interaction to every handler, as if a user pressed a component or used a command
+func (f *fakeDiscord) Interact(i *discordgo.InteractionCreate) {
+	f.mu.Lock()
+	handlers := append([]func(*discordgo.Session, *discordgo.InteractionCreate){}, f.handlers...)
+	f.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(nil, i)
+	}
+}
+
+// Select picks values from a select menu as a user, such as the dates of an availability menu
+func (f *fakeDiscord) Select(userID string, channelID string, customID string, values ...string) {
+	f.Interact(&discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type:      discordgo.InteractionMessageComponent,
+		ChannelID: channelID,
+		User:      &discordgo.User{ID: userID},
+		Data:      discordgo.MessageComponentInteractionData{CustomID: customID, ComponentType: discordgo.SelectMenuComponent, Values: values},
+	}})
+}
+
+// Command uses a subcommand of the '/align' slash command as a user
+func (f *fakeDiscord) Command(userID string, channelID string, name string) {
+	f.Interact(&discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type:      discordgo.InteractionApplicationCommand,
+		ChannelID: channelID,
+		User:      &discordgo.User{ID: userID},
+		Data: discordgo.ApplicationCommandInteractionData{
+			Name:    "align",
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{{Name: name, Type: discordgo.ApplicationCommandOptionSubCommand}},
+		},
+	}})
+}
+
+// Responses returns the responses to interactions, in order
+func (f *fakeDiscord) Responses() []*discordgo.InteractionResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]*discordgo.InteractionResponse{}, f.responses...)
+}
+
+func (f *fakeDiscord) AddHandler(handler interface{}) func() {
+	h, ok := handler.(func(*discordgo.Session, *discordgo.InteractionCreate))
+	if !ok {
+		return func() {}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.handlers = append(f.handlers, h)
+	index := len(f.handlers) - 1
+
+	return func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		f.handlers[index] = func(*discordgo.Session, *discordgo.InteractionCreate) {}
+	}
+}
+
+func (f *fakeDiscord) ApplicationCommandCreate(appID string, guildID string, cmd *discordgo.ApplicationCommand, options ...discordgo.RequestOption) (*discordgo.ApplicationCommand, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.commands[appID] = append(f.commands[appID], cmd)
+	return cmd, nil
+}
+
+func (f *fakeDiscord) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	return &discordgo.Channel{ID: "dm-" + recipientID, Type: discordgo.ChannelTypeDM}, nil
+}
+
+func (f *fakeDiscord) ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return f.record(channelID, content, nil, nil, nil), nil
+}
+
+func (f *fakeDiscord) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return f.record(channelID, data.Content, data.Embeds, data.Components, data.Files), nil
+}
+
+func (f *fakeDiscord) ChannelMessageEditComplex(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return &discordgo.Message{ID: m.ID, ChannelID: m.Channel}, nil
+}
+
+func (f *fakeDiscord) MessageReactionAdd(channelID string, messageID string, emojiID string, options ...discordgo.RequestOption) error {
+	f.React(messageID, emojiID, "bot")
+	return nil
+}
+
+func (f *fakeDiscord) MessageReactions(channelID string, messageID string, emojiID string, limit int, beforeID string, afterID string, options ...discordgo.RequestOption) ([]*discordgo.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Every user fits on a single page, so later pages are empty
+	if afterID != "" {
+		return nil, nil
+	}
+
+	return append([]*discordgo.User{}, f.reactions[messageID][emojiID]...), nil
+}
+
+func (f *fakeDiscord) MessageThreadStart(channelID string, messageID string, name string, archiveDuration int, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	return &discordgo.Channel{ID: "thread-" + messageID, Name: name}, nil
+}
+
+func (f *fakeDiscord) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.responses = append(f.responses, resp)
+	return nil
+}
+
+func (f *fakeDiscord) FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return f.record(interaction.ChannelID, data.Content, data.Embeds, data.Components, data.Files), nil
+}
+
+/* ---- TELEGRAM ---- */
+
+// fakeTelegramMessage represents a message sent on a fake telegram session
+type fakeTelegramMessage struct {
+	ID      int      // The ID of the message
+	ChatID  int64    // The chat the message was sent to
+	Text    string   // The text, caption or poll question of the message
+	PollID  string   // The ID of the poll, if the message is a poll
+	Options []string // The options of the poll, if the message is a poll
+	Photo   bool     // Whether the message is a photo
+}
+
+// fakeTelegram is an in-memory telegram bot. Messages are recorded instead of sent, and updates such as poll answers
+// are scripted with Update
+type fakeTelegram struct {
+	mu       sync.Mutex
+	messages []fakeTelegramMessage
+	requests []telegram.Chattable
+	stopped  []int
+	updates  chan telegram.Update
+}
+
+// Create a fake telegram session
+func newFakeTelegram(t *testing.T) *fakeTelegram {
+	f := &fakeTelegram{updates: make(chan telegram.Update)}
+	t.Cleanup(func() { close(f.updates) })
+
+	return f
+}
+
+// Messages returns the messages sent to a chat, in order
+func (f *fakeTelegram) Messages(chatID int64) []fakeTelegramMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	messages := []fakeTelegramMessage{}
+	for _, message := range f.messages {
+		if message.ChatID == chatID {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages
+}
+
+// Update delivers updates to align and waits until they are handled
+func (f *fakeTelegram) Update(updates ...telegram.Update) {
+	for _, update := range updates {
+		f.updates <- update
+	}
+
+	// Updates are handled one at a time, so once an empty update is received the ones before it are done
+	f.updates <- telegram.Update{}
+}
+
+// Answer a poll as a user, choosing the options with the given indexes
+func (f *fakeTelegram) Answer(pollID string, userID int64, options ...int) {
+	f.Update(telegram.Update{PollAnswer: &telegram.PollAnswer{PollID: pollID, User: telegram.User{ID: userID}, OptionIDs: options}})
+}
+
+// Press a button of an inline keyboard as a user
+func (f *fakeTelegram) Press(chatID int64, messageID int, userID int64, data string) {
+	f.Update(telegram.Update{CallbackQuery: &telegram.CallbackQuery{
+		ID:      fmt.Sprintf("callback-%v", messageID),
+		From:    &telegram.User{ID: userID},
+		Message: &telegram.Message{MessageID: messageID, Chat: &telegram.Chat{ID: chatID}},
+		Data:    data,
+	}})
+}
+
+// Callbacks returns the answers to button presses, in order
+func (f *fakeTelegram) Callbacks() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	callbacks := []string{}
+	for _, request := range f.requests {
+		if c, ok := request.(telegram.CallbackConfig); ok {
+			callbacks = append(callbacks, c.Text)
+		}
+	}
+
+	return callbacks
+}
+
+func (f *fakeTelegram) GetUpdatesChan(config telegram.UpdateConfig) telegram.UpdatesChannel {
+	return f.updates
+}
+
+func (f *fakeTelegram) Send(c telegram.Chattable) (telegram.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	message := fakeTelegramMessage{ID: len(f.messages) + 1}
+	switch c := c.(type) {
+	case telegram.MessageConfig:
+		message.ChatID, message.Text = c.ChatID, c.Text
+	case telegram.SendPollConfig:
+		message.ChatID, message.Text, message.Options = c.ChatID, c.Question, c.Options
+		message.PollID = fmt.Sprintf("poll-%v", message.ID)
+	case telegram.PhotoConfig:
+		message.ChatID, message.Text, message.Photo = c.ChatID, c.Caption, true
+	case telegram.DocumentConfig:
+		message.ChatID, message.Text = c.ChatID, c.Caption
+	default:
+		return telegram.Message{}, fmt.Errorf("fake telegram cannot send %T", c)
+	}
+	f.messages = append(f.messages, message)
+
+	sent := telegram.Message{MessageID: message.ID, Chat: &telegram.Chat{ID: message.ChatID}, Text: message.Text}
+	if message.PollID != "" {
+		sent.Poll = &telegram.Poll{ID: message.PollID, Question: message.Text}
+	}
+
+	return sent, nil
+}
+
+func (f *fakeTelegram) Request(c telegram.Chattable) (*telegram.APIResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, c)
+	return &telegram.APIResponse{Ok: true}, nil
+}
+
+func (f *fakeTelegram) StopPoll(config telegram.StopPollConfig) (telegram.Poll, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.stopped = append(f.stopped, config.MessageID)
+	return telegram.Poll{IsClosed: true}, nil
+}
+
+/* ---- HELPERS ---- */
+
+// Read the credentials of an integration test, skipping the test if they aren't there
+func readTestEnv(t *testing.T, path string) map[string]string {
+	if _, err := os.Stat(path); err != nil {
+		t.Skipf("skipping, no credentials at '%v'", path)
+	}
+
+	env, err := godotenv.Read(path)
+	if err != nil {
+		t.Fatalf("cannot read credentials at '%v' (err: %v)", path, err)
+	}
+
+	return env
+}
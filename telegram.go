@@ -2,10 +2,10 @@ package align
 
 import (
 	"fmt"
+	"html"
 	"log"
 	"strconv"
 	"strings"
-	"time"
 
 	telegram "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"gorm.io/gorm"
@@ -13,9 +13,18 @@ import (
 
 /** ---- TYPES ---- */
 
+// TelegramSession represents the telegram API calls align makes. It is implemented by *telegram.BotAPI, and can be
+// replaced by a fake to run align without connecting to telegram
+type TelegramSession interface {
+	GetUpdatesChan(config telegram.UpdateConfig) telegram.UpdatesChannel
+	Send(c telegram.Chattable) (telegram.Message, error)
+	Request(c telegram.Chattable) (*telegram.APIResponse, error)
+	StopPoll(config telegram.StopPollConfig) (telegram.Poll, error)
+}
+
 // TelegramConfig holds all necessary fields for discord request/response functions to run successfully
 type TelegramConfig struct {
-	Session *telegram.BotAPI
+	Session TelegramSession
 	Updates *telegram.UpdatesChannel
 }
 
@@ -23,33 +32,65 @@ type telegramEntry struct {
 	gorm.Model
 
 	Person    string // The person's name this entry is related to
+	Mode      string // The request mode this entry was sent with
 	Index     int    // The index of this entry
 	PollID    string // The telegram poll ID to get results from
+	ChatID    int64  // The telegram chat ID the poll was sent to
 	MessageID int    // The telegram message ID to get results from
+	Selection string // The person's keyboard selection, with one state character per date
 
 	// The manager this entry is related to
 	Manager   *Manager
 	ManagerID *int
 }
 
-/* ---- GLOBALS ---- */
+// Modes a telegram entry can be sent with
+const (
+	telegramModeDirect   = ""         // Entry sent in a private chat to a single person
+	telegramModeGroup    = "group"    // Entry shared by all persons in a group chat
+	telegramModeKeyboard = "keyboard" // Entry with an inline keyboard sent in a private chat to a single person
+)
 
-var telegramEntries []*telegramEntry
+// States a date can have in a keyboard selection
+const (
+	telegramStateNo    = 'n'
+	telegramStateYes   = 'y'
+	telegramStateMaybe = 'm'
+)
 
-const telegramRequestHeader = `**Schedule for %v**
+// How many dates are shown on a single keyboard message
+const telegramKeyboardSize = 50
 
-Please enter the dates you are free`
+// How long the caption of a photo can be (limited by telegram)
+const telegramCaptionSize = 1024
 
-const telegramResponseBody = `**Schedule results for %v**
+/* ---- GLOBALS ---- */
 
-%v/%v people available
+var telegramEntries []*telegramEntry
 
-%v%v%v`
+// Keys of the telegram messages in the locale catalogs
+const (
+	telegramRequestHeader    = "telegram_request_header"
+	telegramKeyboardHeader   = "telegram_keyboard_header"
+	telegramCalendarNote     = "telegram_calendar_note"
+	telegramCalendarBusy     = "telegram_calendar_busy"
+	telegramKeyboardDone     = "telegram_keyboard_done"
+	telegramHelpBody         = "telegram_help_body"
+	telegramStatusBody       = "telegram_status_body"
+	telegramAvailabilityBody = "telegram_availability_body"
+	telegramResponseBody     = "telegram_response_body"
+	telegramNoResultBody     = "telegram_no_result_body"
+	telegramAnnouncementBody = "telegram_announcement_body"
+	telegramRSVPBody         = "telegram_rsvp_body"
+	telegramReminderBody     = "telegram_reminder_body"
+	telegramApprovalBody     = "telegram_approval_body"
+	telegramEventBody        = "telegram_event_body"
+)
 
 /* ---- FUNCTIONS ---- */
 
 // Initialize a telegram config
-func InitTelegram(manager *Manager, s *telegram.BotAPI) {
+func InitTelegram(manager *Manager, s TelegramSession) {
 	log.Println("[INFO]: initializing telegram config")
 
 	// Add the config
@@ -58,26 +99,42 @@ func InitTelegram(manager *Manager, s *telegram.BotAPI) {
 	}
 
 	if manager.options.UseSQL {
-		// If using SQL, populate telegram entries
-		if !manager.db.Migrator().HasTable(&telegramEntry{}) {
-			if err := manager.db.AutoMigrate(&telegramEntry{}); err != nil {
-				log.Fatalf("[ERR]: cannot migrate telegramEntry object (err: %v)\n", err)
-			}
+		// If using SQL, populate telegram entries. The table is migrated every time so columns added since it was
+		// created are added to it
+		if err := manager.db.AutoMigrate(&telegramEntry{}); err != nil {
+			log.Fatalf("[ERR]: cannot migrate telegramEntry object (err: %v)\n", err)
 		}
 
-		if err := manager.db.Model(&telegramEntry{}).Find(&telegramEntries).Error; err != nil {
+		if err := manager.db.Model(&telegramEntry{}).Where("manager_id = ?", manager.ID).Find(&telegramEntries).Error; err != nil {
 			log.Fatalf("[ERR]: cannot read telegram entries from database (err: %v)\n", err)
 		}
 
 		// Generate a template availability for each person in the entries
 		for _, entry := range telegramEntries {
-			_, ok := manager.availability[entry.Person]
-			if !ok {
-				manager.availability[entry.Person] = manager.generateAvailability()
+			// Restore selections made on keyboards
+			if entry.Mode == telegramModeKeyboard {
+				manager.telegramApplySelection(entry)
+				continue
+			}
+
+			// Group entries are shared by every group person
+			names := []string{entry.Person}
+			if entry.Mode == telegramModeGroup {
+				names = manager.telegramGroupNames()
+			}
+
+			for _, name := range names {
+				if _, ok := manager.availability[name]; !ok {
+					manager.availability[name] = manager.generateAvailability()
+				}
 			}
 		}
 	}
 
+	// Send the reminders and held messages that were missed while align wasn't running, once every module is
+	// initialized
+	manager.resume()
+
 	// Create an update channel and listen for updates
 	u := telegram.NewUpdate(0)
 	u.Timeout = 60
@@ -86,48 +143,24 @@ func InitTelegram(manager *Manager, s *telegram.BotAPI) {
 	go func() {
 		// Process incoming updates
 		for update := range updates {
-			// Discard any message that isn't a poll
-			if update.Poll == nil {
-				continue
-			}
-			poll := update.Poll
-
-			// Find the availability of a person who updated a poll
-			var availability map[string]bool
-			var person Person
-			for _, entry := range telegramEntries {
-				if entry.PollID == poll.ID {
-					// Get the availability of the person
-					a, ok := manager.availability[entry.Person]
-					if !ok {
-						log.Printf("[WARN]: cannot get availability from person '%v'\n", entry.Person)
-						break
-					}
-
-					availability = a
-
-					// Find the associated person from the entry
-					for _, p := range manager.config.Persons {
-						if p.Name == entry.Person {
-							person = p
-							break
-						}
-					}
-					break
-				}
+			// Attribute poll answers to the person who voted
+			if update.PollAnswer != nil {
+				telegramHandlePollAnswer(manager, update.PollAnswer)
 			}
 
-			// If no availability is found, continue
-			if availability == nil {
-				continue
+			// Anonymous polls sent before votes were attributed only report their tally
+			if update.Poll != nil && update.Poll.IsAnonymous {
+				telegramHandlePoll(manager, update.Poll)
 			}
 
-			// Update the person's availability based on the poll results
-			for _, option := range poll.Options {
-				available := option.VoterCount > 0
-				availability[option.Text] = available
+			// Update keyboard selections from button presses
+			if update.CallbackQuery != nil {
+				telegramHandleCallback(manager, s, update.CallbackQuery)
+			}
 
-				log.Printf("[INFO]: availability for '%v' on '%v' is %v\n", person.Name, option.Text, available)
+			// Reply to commands from persons
+			if update.Message != nil && update.Message.IsCommand() {
+				telegramHandleCommand(manager, s, update.Message)
 			}
 		}
 	}()
@@ -159,14 +192,7 @@ func TelegramRequest(person Person, manager *Manager) error {
 	log.Println("[INFO]: generating availability dates")
 
 	// Generate all dates in the availability map
-	year, month, day := manager.ContactDay.Time.Date()
-	today := time.Date(year, month, day, 0, 0, 0, 0, manager.loc)
-
-	dates := []string{}
-	for day := manager.config.Offset; day < manager.config.Interval+manager.config.Offset; day++ {
-		timestamp := today.Add(time.Duration(DAY_DURATION * day)).Format(TIME_FORMAT)
-		dates = append(dates, timestamp)
-	}
+	dates := manager.generateTimestamps()
 
 	log.Println("[INFO]: formatting user ID")
 
@@ -177,21 +203,44 @@ func TelegramRequest(person Person, manager *Manager) error {
 	}
 
 	// Generate the header
-	header := fmt.Sprintf(telegramRequestHeader, manager.config.Title)
+	header := manager.requestHeader(telegramRequestHeader, manager.location(person), "")
+
+	// Polls can't be pre-filled, so list the dates the person's calendar is busy on instead
+	if defaults := manager.loadDefaults(person); defaults != nil {
+		busyString := ""
+		for _, date := range dates {
+			if !defaults[date] {
+				busyString += fmt.Sprintf("- %v\n", manager.formatTimestamp(date))
+			}
+		}
+
+		if busyString != "" {
+			note := manager.text(telegramCalendarBusy, busyString)
+			if _, err := config.Session.Send(telegram.NewMessage(int64(userID), note)); err != nil {
+				return err
+			}
+		}
+	}
 
 	log.Println("[INFO]: sending telegram messages")
 
 	// Send messages
-	for i := 0; i*7 < manager.config.Interval; i++ {
+	for i := 0; i*7 < len(dates); i++ {
 		// Get the dates to send
 		options := []string{}
-		for j := 0; j < 7 && i*7+j < manager.config.Interval; j++ {
-			options = append(options, dates[i*7+j])
+		for j := 0; j < 7 && i*7+j < len(dates); j++ {
+			options = append(options, manager.formatTimestamp(dates[i*7+j]))
+		}
+
+		// Polls need at least two options, which schedules limited to a few weekdays may not have
+		if len(options) == 1 {
+			options = append(options, manager.text("none"))
 		}
 
-		// Create a telegram poll
+		// Create a non-anonymous telegram poll so votes can be attributed to the person
 		poll := telegram.NewPoll(int64(userID), header, options...)
 		poll.AllowsMultipleAnswers = true
+		poll.IsAnonymous = false
 
 		// Send the poll
 		m, err := config.Session.Send(poll)
@@ -204,7 +253,8 @@ func TelegramRequest(person Person, manager *Manager) error {
 			Person:    person.Name,
 			Index:     i,
 			PollID:    m.Poll.ID,
-			MessageID: int(m.Chat.ID),
+			ChatID:    m.Chat.ID,
+			MessageID: m.MessageID,
 			Manager:   manager,
 		}
 		telegramEntries = append(telegramEntries, &entry)
@@ -221,6 +271,180 @@ func TelegramRequest(person Person, manager *Manager) error {
 	return nil
 }
 
+// Request an availability schedule using a shared telegram group chat
+func TelegramGroupRequest(person Person, manager *Manager) error {
+	log.Println("[INFO]: loading telegram config")
+
+	// Attempt to load the telegram config
+	config, ok := manager.moduleConfigs["telegram"].(TelegramConfig)
+	if !ok {
+		return fmt.Errorf("telegram config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("telegram session is nil")
+	}
+
+	// Check if the chat is valid
+	chatID := manager.config.Telegram.ChatID
+	if chatID == 0 {
+		return fmt.Errorf("telegram chat ID is not set")
+	}
+
+	// Generate an availability for the person
+	availability := manager.generateAvailability()
+
+	manager.edit.Lock()
+	manager.availability[person.Name] = availability
+	manager.edit.Unlock()
+
+	// The polls are shared by everyone in the group, so they are only sent once
+	for _, entry := range telegramEntries {
+		if entry.Mode == telegramModeGroup {
+			log.Printf("[INFO]: telegram group polls already sent, skipping '%v'\n", person.Name)
+			return nil
+		}
+	}
+
+	log.Println("[INFO]: generating availability dates")
+
+	// Generate all dates in the availability map
+	dates := manager.generateTimestamps()
+
+	// Generate the header
+	header := manager.requestHeader(telegramRequestHeader, manager.loc, "")
+
+	log.Printf("[INFO]: sending telegram polls to group '%v'\n", chatID)
+
+	// Send messages
+	for i := 0; i*7 < len(dates); i++ {
+		// Get the dates to send
+		options := []string{}
+		for j := 0; j < 7 && i*7+j < len(dates); j++ {
+			options = append(options, manager.formatTimestamp(dates[i*7+j]))
+		}
+
+		// Polls need at least two options, which schedules limited to a few weekdays may not have
+		if len(options) == 1 {
+			options = append(options, manager.text("none"))
+		}
+
+		// Create a non-anonymous telegram poll so votes can be attributed to persons
+		poll := telegram.NewPoll(chatID, header, options...)
+		poll.AllowsMultipleAnswers = true
+		poll.IsAnonymous = false
+
+		// Send the poll
+		m, err := config.Session.Send(poll)
+		if err != nil {
+			return err
+		}
+
+		// Add this message as a recorded entry
+		entry := telegramEntry{
+			Mode:      telegramModeGroup,
+			Index:     i,
+			PollID:    m.Poll.ID,
+			ChatID:    chatID,
+			MessageID: m.MessageID,
+			Manager:   manager,
+		}
+		telegramEntries = append(telegramEntries, &entry)
+
+		// If using SQL, add to SQL database
+		if manager.options.UseSQL {
+			log.Println("[INFO]: adding telegram entry to SQL")
+			if err := manager.db.Save(&entry).Error; err != nil {
+				log.Printf("[ERR]: error saving telegram entry to SQL (err: %v)\n", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Request an availability schedule using a telegram inline keyboard
+func TelegramKeyboardRequest(person Person, manager *Manager) error {
+	log.Println("[INFO]: loading telegram config")
+
+	// Attempt to load the telegram config
+	config, ok := manager.moduleConfigs["telegram"].(TelegramConfig)
+	if !ok {
+		return fmt.Errorf("telegram config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("telegram session is nil")
+	}
+
+	// Generate an availability for the person
+	manager.edit.Lock()
+	manager.availability[person.Name] = manager.generateAvailability()
+	manager.tentative[person.Name] = manager.generateAvailability()
+	manager.edit.Unlock()
+
+	log.Println("[INFO]: generating availability dates")
+
+	// Generate all dates in the availability map
+	dates := manager.generateTimestamps()
+
+	log.Println("[INFO]: formatting user ID")
+
+	// Format user ID
+	userID, err := strconv.Atoi(person.ID)
+	if err != nil {
+		return err
+	}
+
+	// Pre-select the dates the person's calendar is free on
+	defaults := manager.loadDefaults(person)
+	if defaults != nil {
+		if _, err := config.Session.Send(telegram.NewMessage(int64(userID), manager.text(telegramCalendarNote))); err != nil {
+			return err
+		}
+	}
+
+	log.Println("[INFO]: sending telegram keyboards")
+
+	// Send messages
+	for i := 0; i*telegramKeyboardSize < len(dates); i++ {
+		entry := telegramEntry{
+			Person:    person.Name,
+			Mode:      telegramModeKeyboard,
+			Index:     i,
+			ChatID:    int64(userID),
+			Selection: telegramSelection(defaults, dates, i),
+			Manager:   manager,
+		}
+
+		// Send the keyboard
+		data := manager.requestData(manager.location(person), "")
+		msg := telegramHTMLMessage(int64(userID), telegramRender(manager, templateHeader, data, telegramKeyboardHeader, data.Title, data.Deadline))
+		msg.ReplyMarkup = telegramKeyboardMarkup(manager, &entry, dates)
+
+		m, err := config.Session.Send(msg)
+		if err != nil {
+			return err
+		}
+
+		// Add this message as a recorded entry
+		entry.MessageID = m.MessageID
+		telegramEntries = append(telegramEntries, &entry)
+
+		// If using SQL, add to SQL database
+		if manager.options.UseSQL {
+			log.Println("[INFO]: adding telegram entry to SQL")
+			if err := manager.db.Save(&entry).Error; err != nil {
+				log.Printf("[ERR]: error saving telegram entry to SQL (err: %v)\n", err)
+			}
+		}
+	}
+
+	return nil
+}
+
 // Read a response for availability using telegram
 func TelegramGather(person Person, manager *Manager) error {
 	log.Println("[INFO]: loading telegram config")
@@ -250,7 +474,7 @@ func TelegramGather(person Person, manager *Manager) error {
 	var entries []*telegramEntry
 	for i := 0; i < len(telegramEntries); i++ {
 		// On matching entry, add to local array and remove from global
-		if telegramEntries[i].Person == person.Name {
+		if telegramEntries[i].Mode == telegramModeDirect && telegramEntries[i].Person == person.Name {
 			entries = append(entries, telegramEntries[i])
 
 			// If using SQL, remove from SQL database
@@ -266,22 +490,19 @@ func TelegramGather(person Person, manager *Manager) error {
 	}
 
 	// Sort entries based on index
-	for i := 1; i < len(entries); i++ {
-		cur := entries[i]
-		j := i - 1
-
-		for j >= 0 && entries[j].Index > cur.Index {
-			entries[j+1] = entries[j]
-			j--
-		}
-		entries[j+1] = cur
-	}
+	sortTelegramEntries(entries)
 
 	log.Printf("[INFO]: stopping telegram polls for '%v'\n", person.Name)
 
 	for _, entry := range entries {
+		// Entries saved before chat IDs were recorded were always sent to the user's private chat
+		chatID := entry.ChatID
+		if chatID == 0 {
+			chatID = int64(userID)
+		}
+
 		// Stop the poll represented by this entry
-		_, err := config.Session.StopPoll(telegram.NewStopPoll(int64(userID), entry.MessageID))
+		_, err := config.Session.StopPoll(telegram.NewStopPoll(chatID, entry.MessageID))
 		if err != nil {
 			return err
 		}
@@ -301,8 +522,8 @@ func TelegramGather(person Person, manager *Manager) error {
 	return nil
 }
 
-// Send a user a response summary on telegram
-func TelegramResponse(person Person, manager *Manager, days []day, unknowns []string, available int) error {
+// Read a response for availability from a telegram inline keyboard
+func TelegramKeyboardGather(person Person, manager *Manager) error {
 	log.Println("[INFO]: loading telegram config")
 
 	// Attempt to load the telegram config
@@ -316,48 +537,967 @@ func TelegramResponse(person Person, manager *Manager, days []day, unknowns []st
 		return fmt.Errorf("telegram session is nil")
 	}
 
-	log.Println("[INFO]: building response string")
+	log.Printf("[INFO]: collecting telegram entries for '%v'", person.Name)
 
-	// Concatenate days to a single string
-	dayString := ""
-	for _, day := range days {
-		dayString += fmt.Sprintf("- %v (%v)\n", day.Timestamp, strings.Join(day.AvailablePersons, ", "))
+	// Filter for entries for this specific person
+	var entries []*telegramEntry
+	for i := 0; i < len(telegramEntries); i++ {
+		// On matching entry, add to local array and remove from global
+		if telegramEntries[i].Mode == telegramModeKeyboard && telegramEntries[i].Person == person.Name {
+			entries = append(entries, telegramEntries[i])
+
+			// If using SQL, remove from SQL database
+			if manager.options.UseSQL {
+				if err := manager.db.Delete(&telegramEntries[i]).Error; err != nil {
+					log.Printf("[ERR]: error deleting telegram entry from SQL (err: %v)\n", err)
+				}
+			}
+
+			telegramEntries = append(telegramEntries[:i], telegramEntries[i+1:]...)
+			i--
+		}
 	}
 
-	// Concatenate unknowns into a single string
-	unknownsString := ""
-	for _, person := range unknowns {
-		unknownsString += fmt.Sprintf("- %v\n", person)
+	log.Printf("[INFO]: closing telegram keyboards for '%v'\n", person.Name)
+
+	for _, entry := range entries {
+		// Apply the final selection of the keyboard
+		manager.telegramApplySelection(entry)
+
+		// Remove the keyboard so the selection can't be changed anymore
+		edit := telegram.NewEditMessageReplyMarkup(entry.ChatID, entry.MessageID, telegram.InlineKeyboardMarkup{
+			InlineKeyboard: [][]telegram.InlineKeyboardButton{},
+		})
+		if _, err := config.Session.Request(edit); err != nil {
+			log.Printf("[ERR]: error closing telegram keyboard (err: %v)\n", err)
+		}
 	}
 
-	unknownPrefix := ""
-	if len(unknowns) > 0 {
-		unknownPrefix = "\nNo responses from:\n"
+	// Log the user's availability
+	manager.edit.Lock()
+	for date, status := range manager.availability[person.Name] {
+		log.Printf("[INFO]: user '%v' availability status on %v is %v (maybe %v)\n", person.Name, date, status, manager.tentative[person.Name][date])
 	}
+	manager.edit.Unlock()
 
-	// Format user ID
-	userID, err := strconv.Atoi(person.ID)
-	if err != nil {
-		return err
+	return nil
+}
+
+// Read a response for availability from a shared telegram group chat
+func TelegramGroupGather(person Person, manager *Manager) error {
+	log.Println("[INFO]: loading telegram config")
+
+	// Attempt to load the telegram config
+	config, ok := manager.moduleConfigs["telegram"].(TelegramConfig)
+	if !ok {
+		return fmt.Errorf("telegram config has not been initialized")
 	}
 
-	// Format the message to be send
-	str := fmt.Sprintf(telegramResponseBody,
-		manager.config.Title,
-		available,
-		len(manager.config.Persons),
-		dayString,
-		unknownPrefix,
-		unknownsString,
-	)
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("telegram session is nil")
+	}
 
-	log.Printf("[INFO]: sending response message\n%v\n", str)
+	// Poll answers are collected as they arrive, so the person's availability is already up to date
+	manager.edit.Lock()
+	availability, ok := manager.availability[person.Name]
+	manager.edit.Unlock()
+	if !ok {
+		return fmt.Errorf("cannot find availability for '%v'", person.Name)
+	}
 
-	// Send a message to the user
-	_, err = config.Session.Send(telegram.NewMessage(int64(userID), str))
-	if err != nil {
-		return err
+	// Log the user's availability
+	for date, status := range availability {
+		log.Printf("[INFO]: user '%v' availability status on %v is %v\n", person.Name, date, status)
 	}
 
 	return nil
 }
+
+// Send a user a response summary on telegram
+func TelegramResponse(person Person, manager *Manager, days []day, unknowns []string, available int) error {
+	log.Println("[INFO]: loading telegram config")
+
+	// Attempt to load the telegram config
+	config, ok := manager.moduleConfigs["telegram"].(TelegramConfig)
+	if !ok {
+		return fmt.Errorf("telegram config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("telegram session is nil")
+	}
+
+	// Format user ID
+	userID, err := strconv.Atoi(person.ID)
+	if err != nil {
+		return err
+	}
+
+	// Format the message to be send
+	str := telegramFormatResponse(manager, days, unknowns, available)
+
+	log.Printf("[INFO]: sending response message\n%v\n", str)
+
+	// Send a message to the user
+	return telegramSendResponse(config, manager, int64(userID), str, days)
+}
+
+// Send a response summary to a shared telegram group chat
+func TelegramGroupResponse(person Person, manager *Manager, days []day, unknowns []string, available int) error {
+	log.Println("[INFO]: loading telegram config")
+
+	// Attempt to load the telegram config
+	config, ok := manager.moduleConfigs["telegram"].(TelegramConfig)
+	if !ok {
+		return fmt.Errorf("telegram config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("telegram session is nil")
+	}
+
+	// Check if the chat is valid
+	chatID := manager.config.Telegram.ChatID
+	if chatID == 0 {
+		return fmt.Errorf("telegram chat ID is not set")
+	}
+
+	// Collect the shared group entries and remove them, so the response is only sent once
+	var entries []*telegramEntry
+	for i := 0; i < len(telegramEntries); i++ {
+		if telegramEntries[i].Mode == telegramModeGroup {
+			entries = append(entries, telegramEntries[i])
+
+			// If using SQL, remove from SQL database
+			if manager.options.UseSQL {
+				if err := manager.db.Delete(&telegramEntries[i]).Error; err != nil {
+					log.Printf("[ERR]: error deleting telegram entry from SQL (err: %v)\n", err)
+				}
+			}
+
+			telegramEntries = append(telegramEntries[:i], telegramEntries[i+1:]...)
+			i--
+		}
+	}
+
+	if len(entries) == 0 {
+		log.Printf("[INFO]: telegram group response already sent, skipping '%v'\n", person.Name)
+		return nil
+	}
+
+	log.Println("[INFO]: stopping telegram group polls")
+
+	for _, entry := range entries {
+		// Stop the poll represented by this entry
+		if _, err := config.Session.StopPoll(telegram.NewStopPoll(entry.ChatID, entry.MessageID)); err != nil {
+			log.Printf("[ERR]: error stopping telegram group poll (err: %v)\n", err)
+		}
+	}
+
+	// Format the message to be send
+	str := telegramFormatResponse(manager, days, unknowns, available)
+
+	log.Printf("[INFO]: sending group response message\n%v\n", str)
+
+	// Send a message to the group
+	return telegramSendResponse(config, manager, chatID, str, days)
+}
+
+// Tell a person which day was decided on using telegram, asking whether they are going if confirmations are enabled
+func TelegramAnnounce(person Person, manager *Manager, event Event) error {
+	log.Println("[INFO]: loading telegram config")
+
+	// Attempt to load the telegram config
+	config, ok := manager.moduleConfigs["telegram"].(TelegramConfig)
+	if !ok {
+		return fmt.Errorf("telegram config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("telegram session is nil")
+	}
+
+	// Format user ID
+	userID, err := strconv.Atoi(person.ID)
+	if err != nil {
+		return err
+	}
+
+	return telegramSendAnnouncement(config, manager, int64(userID), "", event, manager.eventWhen(event, person))
+}
+
+// Tell a shared telegram group chat which day was decided on, asking whether persons are going if confirmations are
+// enabled
+func TelegramGroupAnnounce(person Person, manager *Manager, event Event) error {
+	log.Println("[INFO]: loading telegram config")
+
+	// Attempt to load the telegram config
+	config, ok := manager.moduleConfigs["telegram"].(TelegramConfig)
+	if !ok {
+		return fmt.Errorf("telegram config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("telegram session is nil")
+	}
+
+	// Check if the chat is valid
+	chatID := manager.config.Telegram.ChatID
+	if chatID == 0 {
+		return fmt.Errorf("telegram chat ID is not set")
+	}
+
+	// Anyone in the group can press the buttons, so the person is named to know the question is for them
+	return telegramSendAnnouncement(config, manager, chatID, person.Name+", ", event, manager.eventWhen(event, person))
+}
+
+// Send the decided event to a telegram chat, with going and not going buttons if confirmations are enabled
+func telegramSendAnnouncement(config TelegramConfig, manager *Manager, chatID int64, prefix string, event Event, when string) error {
+	log.Printf("[INFO]: sending telegram announcement to chat '%v'\n", chatID)
+
+	msg := telegramHTMLMessage(chatID, telegramText(manager, telegramAnnouncementBody, prefix, event.Title, when))
+	if manager.config.Confirm {
+		msg.Text += manager.text(telegramRSVPBody)
+		msg.ReplyMarkup = telegram.NewInlineKeyboardMarkup(telegram.NewInlineKeyboardRow(
+			telegram.NewInlineKeyboardButtonData(manager.text("going"), "align:rsvp:yes"),
+			telegram.NewInlineKeyboardButtonData(manager.text("not_going"), "align:rsvp:no"),
+		))
+	}
+
+	_, err := config.Session.Send(msg)
+	return err
+}
+
+// Remind a person of the decided event using telegram
+func TelegramRemind(person Person, manager *Manager, event Event) error {
+	log.Println("[INFO]: loading telegram config")
+
+	// Attempt to load the telegram config
+	config, ok := manager.moduleConfigs["telegram"].(TelegramConfig)
+	if !ok {
+		return fmt.Errorf("telegram config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("telegram session is nil")
+	}
+
+	// Format user ID
+	userID, err := strconv.Atoi(person.ID)
+	if err != nil {
+		return err
+	}
+
+	when := manager.eventWhen(event, person)
+	_, err = config.Session.Send(telegramHTMLMessage(int64(userID), telegramRender(manager, templateReminder, manager.eventData(event, when, ""), telegramReminderBody, "", event.Title, when)))
+	return err
+}
+
+// Remind a person of the decided event in a shared telegram group chat
+func TelegramGroupRemind(person Person, manager *Manager, event Event) error {
+	log.Println("[INFO]: loading telegram config")
+
+	// Attempt to load the telegram config
+	config, ok := manager.moduleConfigs["telegram"].(TelegramConfig)
+	if !ok {
+		return fmt.Errorf("telegram config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("telegram session is nil")
+	}
+
+	// Check if the chat is valid
+	chatID := manager.config.Telegram.ChatID
+	if chatID == 0 {
+		return fmt.Errorf("telegram chat ID is not set")
+	}
+
+	when := manager.eventWhen(event, person)
+	_, err := config.Session.Send(telegramHTMLMessage(chatID, telegramRender(manager, templateReminder, manager.eventData(event, when, person.Name), telegramReminderBody, person.Name+", ", event.Title, when)))
+	return err
+}
+
+// Send an organizer the results and buttons to pick the final day with, using telegram
+func TelegramApprove(person Person, manager *Manager, days []day, unknowns []string, available int) error {
+	log.Println("[INFO]: loading telegram config")
+
+	// Attempt to load the telegram config
+	config, ok := manager.moduleConfigs["telegram"].(TelegramConfig)
+	if !ok {
+		return fmt.Errorf("telegram config has not been initialized")
+	}
+
+	// Check if the session is valid
+	if config.Session == nil {
+		return fmt.Errorf("telegram session is nil")
+	}
+
+	// Organizers always decide in private, even if the group uses a shared chat
+	userID, err := strconv.Atoi(person.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := telegramSendResults(config, manager, int64(userID), telegramFormatResponse(manager, days, unknowns, available)); err != nil {
+		return err
+	}
+
+	// Every date can be picked, so organizers can override the best days
+	best := map[string]bool{}
+	for _, d := range days {
+		best[d.Timestamp] = true
+	}
+
+	rows := [][]telegram.InlineKeyboardButton{}
+	for _, date := range manager.generateTimestamps() {
+		label := manager.formatTimestamp(date)
+		if best[date] {
+			label = "⭐ " + label
+		}
+
+		rows = append(rows, telegram.NewInlineKeyboardRow(telegram.NewInlineKeyboardButtonData(label, fmt.Sprintf("align:pick:%v", date))))
+	}
+
+	log.Println("[INFO]: sending telegram approval keyboard")
+
+	msg := telegramHTMLMessage(int64(userID), telegramText(manager, telegramApprovalBody, manager.config.Title))
+	msg.ReplyMarkup = telegram.NewInlineKeyboardMarkup(rows...)
+
+	_, err = config.Session.Send(msg)
+	return err
+}
+
+// Send a response summary to a telegram chat, with the heatmap of everyone's availability as its photo if there is one
+func telegramSendResults(config TelegramConfig, manager *Manager, chatID int64, str string) error {
+	heatmap, ok := manager.Heatmap()
+
+	// Captions are shorter than messages, so long summaries are sent before the photo
+	if !ok || len([]rune(str)) > telegramCaptionSize {
+		if _, err := config.Session.Send(telegramHTMLMessage(chatID, str)); err != nil {
+			return err
+		}
+		str = ""
+	}
+
+	if ok {
+		photo := telegram.NewPhoto(chatID, telegram.FileBytes{Name: "heatmap.png", Bytes: heatmap})
+		photo.Caption = str
+		photo.ParseMode = telegram.ModeHTML
+
+		if _, err := config.Session.Send(photo); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Send a response summary to a telegram chat, attaching a calendar file for the best day if requested
+func telegramSendResponse(config TelegramConfig, manager *Manager, chatID int64, str string, days []day) error {
+	if err := telegramSendResults(config, manager, chatID, str); err != nil {
+		return err
+	}
+
+	if manager.config.AttachCalendar && len(days) > 0 {
+		log.Println("[INFO]: attaching calendar file")
+
+		document := telegram.NewDocument(chatID, telegram.FileBytes{
+			Name:  "align.ics",
+			Bytes: manager.newEvent(days[0]).ICS(),
+		})
+		if _, err := config.Session.Send(document); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Format a response summary for telegram
+func telegramFormatResponse(manager *Manager, days []day, unknowns []string, available int) string {
+	log.Println("[INFO]: building response string")
+
+	// Concatenate days to a single string
+	dayString := ""
+	for _, day := range days {
+		dayString += fmt.Sprintf("- %v (%v)", manager.formatTimestamp(day.Timestamp), strings.Join(day.AvailablePersons, ", "))
+		if len(day.TentativePersons) > 0 {
+			dayString += " " + manager.text("maybe", strings.Join(day.TentativePersons, ", "))
+		}
+		dayString += "\n"
+	}
+
+	// Concatenate unknowns into a single string
+	unknownsString := ""
+	for _, person := range unknowns {
+		unknownsString += fmt.Sprintf("- %v\n", person)
+	}
+
+	unknownPrefix := ""
+	if len(unknowns) > 0 {
+		unknownPrefix = "\n" + manager.text("no_responses") + "\n"
+	}
+
+	// Explain why excluded dates weren't asked about
+	excludedString := ""
+	if excluded := manager.excludedDates(); len(excluded) > 0 {
+		excludedString = "\n" + manager.text("not_asked") + "\n"
+		for _, date := range excluded {
+			excludedString += fmt.Sprintf("- %v\n", date)
+		}
+	}
+
+	// No day works when nobody is available on any date
+	data := manager.resultData(days, unknowns, available)
+	if len(days) == 0 {
+		return telegramRender(manager, templateNoResult, data, telegramNoResultBody,
+			manager.config.Title,
+			unknownPrefix,
+			unknownsString,
+			excludedString,
+		)
+	}
+
+	return telegramRender(manager, templateResult, data, telegramResponseBody,
+		manager.config.Title,
+		available,
+		len(manager.config.Persons),
+		dayString,
+		unknownPrefix,
+		unknownsString,
+		excludedString,
+	)
+}
+
+// Update a person's availability from a poll answer. Answers are attributed to the user who voted rather
+// than the person the poll was sent to, so shared or forwarded polls only change the voter's availability
+func telegramHandlePollAnswer(manager *Manager, answer *telegram.PollAnswer) {
+	// Find the entry the answer belongs to
+	var entry *telegramEntry
+	for _, e := range telegramEntries {
+		if e.PollID == answer.PollID {
+			entry = e
+			break
+		}
+	}
+
+	if entry == nil {
+		return
+	}
+
+	// Find the person who answered the poll
+	person, ok := manager.telegramPerson(answer.User.ID)
+	if !ok {
+		log.Printf("[WARN]: poll answer from unknown telegram user '%v'\n", answer.User.ID)
+		return
+	}
+
+	// Mark the dates the person chose. If the person retracted their vote, no options are chosen
+	chosen := map[int]bool{}
+	for _, id := range answer.OptionIDs {
+		chosen[id] = true
+	}
+
+	dates := manager.generateTimestamps()
+
+	manager.edit.Lock()
+	defer manager.edit.Unlock()
+
+	availability, ok := manager.availability[person.Name]
+	if !ok {
+		log.Printf("[WARN]: cannot get availability from person '%v'\n", person.Name)
+		return
+	}
+
+	manager.responded[person.Name] = true
+
+	for j := 0; j < 7 && entry.Index*7+j < len(dates); j++ {
+		date := dates[entry.Index*7+j]
+		availability[date] = chosen[j]
+
+		log.Printf("[INFO]: availability for '%v' on '%v' is %v\n", person.Name, date, chosen[j])
+	}
+}
+
+// Update a person's availability from the tally of an anonymous private poll. Polls sent before votes were
+// attributed are anonymous, so their tally is the only way to read the answers of the person they were sent to
+func telegramHandlePoll(manager *Manager, poll *telegram.Poll) {
+	// Find the entry the poll belongs to. Tallies of group polls can't be attributed to anyone
+	var entry *telegramEntry
+	for _, e := range telegramEntries {
+		if e.PollID == poll.ID && e.Mode == telegramModeDirect {
+			entry = e
+			break
+		}
+	}
+
+	if entry == nil {
+		return
+	}
+
+	dates := manager.generateTimestamps()
+
+	manager.edit.Lock()
+	defer manager.edit.Unlock()
+
+	availability, ok := manager.availability[entry.Person]
+	if !ok {
+		log.Printf("[WARN]: cannot get availability from person '%v'\n", entry.Person)
+		return
+	}
+
+	for j, option := range poll.Options {
+		if entry.Index*7+j >= len(dates) {
+			break
+		}
+
+		date := dates[entry.Index*7+j]
+		availability[date] = option.VoterCount > 0
+		manager.responded[entry.Person] = manager.responded[entry.Person] || availability[date]
+
+		log.Printf("[INFO]: availability for '%v' on '%v' is %v\n", entry.Person, date, availability[date])
+	}
+}
+
+// Update a person's keyboard selection from a button press
+func telegramHandleCallback(manager *Manager, s TelegramSession, query *telegram.CallbackQuery) {
+	// Callback data is formatted as 'align:<date index>', 'align:done', 'align:rsvp:<yes|no>' or 'align:pick:<date>'
+	data, ok := strings.CutPrefix(query.Data, "align:")
+	if !ok || query.Message == nil {
+		return
+	}
+
+	// Lock in the day an organizer picked
+	if date, ok := strings.CutPrefix(data, "pick:"); ok {
+		telegramHandlePick(manager, s, query, date)
+		return
+	}
+
+	// Record whether the person is going to the decided event
+	if answer, ok := strings.CutPrefix(data, "rsvp:"); ok {
+		text := manager.text("no_upcoming_event")
+		if person, ok := manager.telegramPerson(query.From.ID); !ok {
+			text = manager.text("not_in_schedule")
+		} else if err := manager.confirm(person.Name, answer == "yes"); err == nil {
+			text = manager.text("thanks")
+			if answer == "yes" {
+				text = manager.text("see_you_there")
+			}
+		}
+
+		if _, err := s.Request(telegram.NewCallback(query.ID, text)); err != nil {
+			log.Printf("[ERR]: error answering telegram callback (err: %v)\n", err)
+		}
+		return
+	}
+
+	// Find the entry the keyboard belongs to
+	var entry *telegramEntry
+	for _, e := range telegramEntries {
+		if e.Mode == telegramModeKeyboard && e.ChatID == query.Message.Chat.ID && e.MessageID == query.Message.MessageID {
+			entry = e
+			break
+		}
+	}
+
+	if entry == nil {
+		if _, err := s.Request(telegram.NewCallback(query.ID, manager.text("schedule_closed"))); err != nil {
+			log.Printf("[ERR]: error answering telegram callback (err: %v)\n", err)
+		}
+		return
+	}
+
+	// Only the person the keyboard was sent to can change it
+	person, ok := manager.telegramPerson(query.From.ID)
+	if !ok || person.Name != entry.Person {
+		if _, err := s.Request(telegram.NewCallback(query.ID, manager.text("telegram_not_yours"))); err != nil {
+			log.Printf("[ERR]: error answering telegram callback (err: %v)\n", err)
+		}
+		return
+	}
+
+	// The keyboard can't be used if the schedule has changed since it was sent, such as when a new schedule started
+	dates := manager.generateTimestamps()
+	if entry.Index*telegramKeyboardSize+len(entry.Selection) > len(dates) {
+		if _, err := s.Request(telegram.NewCallback(query.ID, manager.text("schedule_closed"))); err != nil {
+			log.Printf("[ERR]: error answering telegram callback (err: %v)\n", err)
+		}
+		return
+	}
+
+	// Finish the selection
+	if data == "done" {
+		manager.telegramApplySelection(entry)
+		manager.markResponded(person.Name)
+
+		// Summarize the selection in place of the keyboard
+		summary := ""
+		for j, state := range []byte(entry.Selection) {
+			summary += fmt.Sprintf("%v %v\n", telegramStateIcon(state), manager.formatTimestamp(dates[entry.Index*telegramKeyboardSize+j]))
+		}
+
+		edit := telegram.NewEditMessageText(entry.ChatID, entry.MessageID, telegramText(manager, telegramKeyboardDone, manager.config.Title, summary))
+		edit.ParseMode = telegram.ModeHTML
+		if _, err := s.Request(edit); err != nil {
+			log.Printf("[ERR]: error editing telegram keyboard (err: %v)\n", err)
+		}
+
+		if _, err := s.Request(telegram.NewCallback(query.ID, manager.text("telegram_saved"))); err != nil {
+			log.Printf("[ERR]: error answering telegram callback (err: %v)\n", err)
+		}
+		return
+	}
+
+	// Find the date that was pressed
+	j, err := strconv.Atoi(data)
+	if err != nil || j < 0 || j >= len(entry.Selection) {
+		log.Printf("[WARN]: invalid telegram callback data '%v'\n", query.Data)
+		return
+	}
+
+	// Cycle the date's state from no to yes to maybe
+	selection := []byte(entry.Selection)
+	switch selection[j] {
+	case telegramStateNo:
+		selection[j] = telegramStateYes
+	case telegramStateYes:
+		selection[j] = telegramStateMaybe
+	default:
+		selection[j] = telegramStateNo
+	}
+	entry.Selection = string(selection)
+
+	manager.telegramApplySelection(entry)
+	manager.markResponded(person.Name)
+
+	// If using SQL, save the selection
+	if manager.options.UseSQL {
+		if err := manager.db.Save(entry).Error; err != nil {
+			log.Printf("[ERR]: error saving telegram entry to SQL (err: %v)\n", err)
+		}
+	}
+
+	// Redraw the keyboard in place
+	edit := telegram.NewEditMessageReplyMarkup(entry.ChatID, entry.MessageID, telegramKeyboardMarkup(manager, entry, dates))
+	if _, err := s.Request(edit); err != nil {
+		log.Printf("[ERR]: error editing telegram keyboard (err: %v)\n", err)
+	}
+
+	date := dates[entry.Index*telegramKeyboardSize+j]
+	if _, err := s.Request(telegram.NewCallback(query.ID, fmt.Sprintf("%v %v", telegramStateIcon(selection[j]), manager.formatTimestamp(date)))); err != nil {
+		log.Printf("[ERR]: error answering telegram callback (err: %v)\n", err)
+	}
+}
+
+// Lock in the day an organizer picked from their approval keyboard
+func telegramHandlePick(manager *Manager, s TelegramSession, query *telegram.CallbackQuery, date string) {
+	answer := func(text string) {
+		if _, err := s.Request(telegram.NewCallback(query.ID, text)); err != nil {
+			log.Printf("[ERR]: error answering telegram callback (err: %v)\n", err)
+		}
+	}
+
+	person, ok := manager.telegramPerson(query.From.ID)
+	if !ok || !person.Organizer {
+		answer(manager.text("only_organizers"))
+		return
+	}
+
+	// The keyboard may be from an earlier schedule, or the day may already have been picked by another organizer
+	if !manager.scheduled(date) {
+		answer(manager.text("schedule_closed"))
+		return
+	}
+
+	if err := manager.decide(date, true); err != nil {
+		log.Printf("[WARN]: cannot decide on '%v' (err: %v)\n", date, err)
+		answer(manager.text("already_picked"))
+		return
+	}
+
+	answer(manager.text("decided", manager.formatTimestamp(date)))
+
+	// Replace the keyboard with the decision so it can't be picked twice
+	edit := telegram.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, manager.text("decided", manager.formatTimestamp(date)))
+	if _, err := s.Request(edit); err != nil {
+		log.Printf("[ERR]: error editing telegram approval keyboard (err: %v)\n", err)
+	}
+}
+
+// Reply to a command sent by a person
+func telegramHandleCommand(manager *Manager, s TelegramSession, message *telegram.Message) {
+	if message.From == nil {
+		return
+	}
+
+	// Replies are formatted with HTML, so values in them have to be escaped
+	reply := func(text string) {
+		msg := telegramHTMLMessage(message.Chat.ID, text)
+		msg.ReplyToMessageID = message.MessageID
+
+		if _, err := s.Send(msg); err != nil {
+			log.Printf("[ERR]: error replying to telegram command (err: %v)\n", err)
+		}
+	}
+
+	// Find the person who sent the command
+	person, ok := manager.telegramPerson(message.From.ID)
+	if !ok {
+		reply(manager.text("not_in_schedule"))
+		return
+	}
+
+	log.Printf("[INFO]: '%v' used telegram command '%v'\n", person.Name, message.Command())
+
+	switch message.Command() {
+	case "start", "help":
+		reply(manager.text(telegramHelpBody))
+
+	case "results":
+		manager.edit.Lock()
+		r := manager.LastResult
+		manager.edit.Unlock()
+
+		if r == nil {
+			reply(manager.text("no_results"))
+			return
+		}
+
+		reply(telegramFormatResponse(manager, r.Days, r.Unknowns, r.Available))
+
+	case "event":
+		reply(telegramFormatEvent(manager))
+
+	case "status", "myavailability", "skip":
+		if !manager.ContactDay.Valid {
+			reply(manager.text("no_schedule"))
+			return
+		}
+
+		switch message.Command() {
+		case "status":
+			reply(telegramFormatStatus(manager, person))
+		case "myavailability":
+			reply(telegramFormatAvailability(manager, person))
+		case "skip":
+			manager.skip(person.Name)
+			reply(telegramText(manager, "telegram_opted_out", manager.config.Title))
+		}
+
+	default:
+		reply(manager.text("telegram_unknown_command"))
+	}
+}
+
+// Format the current schedule and who is still pending for telegram
+func telegramFormatStatus(manager *Manager, person Person) string {
+	_, _, answered := manager.current(person)
+
+	manager.edit.Lock()
+	skipped := manager.skipped[person.Name]
+	manager.edit.Unlock()
+
+	// Describe the person's own answer
+	answerString := manager.text("answered")
+	switch {
+	case skipped:
+		answerString = manager.text("opted_out")
+	case !answered:
+		answerString = manager.text("not_answered")
+	}
+
+	// List the persons who haven't answered yet
+	pendingString := manager.text("everyone_answered")
+	if pending := manager.pending(); len(pending) > 0 {
+		pendingString = manager.text("still_waiting", strings.Join(pending, ", "))
+	}
+
+	return telegramText(manager, telegramStatusBody, manager.config.Title, answerString, pendingString)
+}
+
+// Format who is going to the decided event for telegram
+func telegramFormatEvent(manager *Manager) string {
+	manager.edit.Lock()
+	event := manager.Decision
+	manager.edit.Unlock()
+
+	if event == nil {
+		return manager.text("no_event")
+	}
+
+	going, notGoing, pending := manager.confirmations()
+
+	return telegramText(manager, telegramEventBody,
+		event.Title,
+		manager.FormatDate(event.Date),
+		manager.listNames(going),
+		manager.listNames(notGoing),
+		manager.listNames(pending),
+	)
+}
+
+// Format a person's current answer for telegram
+func telegramFormatAvailability(manager *Manager, person Person) string {
+	availability, tentative, _ := manager.current(person)
+
+	dateString := ""
+	for _, date := range manager.generateTimestamps() {
+		state := byte(telegramStateNo)
+		if availability[date] {
+			state = telegramStateYes
+		} else if tentative[date] {
+			state = telegramStateMaybe
+		}
+
+		dateString += fmt.Sprintf("%v %v\n", telegramStateIcon(state), manager.formatTimestamp(date))
+	}
+
+	return telegramText(manager, telegramAvailabilityBody, manager.config.Title, dateString)
+}
+
+// Build the inline keyboard for a keyboard entry, with one button per date and a done button
+func telegramKeyboardMarkup(manager *Manager, entry *telegramEntry, dates []string) telegram.InlineKeyboardMarkup {
+	rows := [][]telegram.InlineKeyboardButton{}
+	for j, state := range []byte(entry.Selection) {
+		// Dates that are no longer being asked about don't get a button
+		if entry.Index*telegramKeyboardSize+j >= len(dates) {
+			break
+		}
+
+		text := fmt.Sprintf("%v %v", telegramStateIcon(state), manager.formatTimestamp(dates[entry.Index*telegramKeyboardSize+j]))
+		rows = append(rows, telegram.NewInlineKeyboardRow(telegram.NewInlineKeyboardButtonData(text, fmt.Sprintf("align:%v", j))))
+	}
+	rows = append(rows, telegram.NewInlineKeyboardRow(telegram.NewInlineKeyboardButtonData(manager.text("telegram_done"), "align:done")))
+
+	return telegram.NewInlineKeyboardMarkup(rows...)
+}
+
+// Build the selection for a keyboard from an availability
+func telegramSelection(availability map[string]bool, dates []string, index int) string {
+	selection := []byte{}
+	for j := index * telegramKeyboardSize; j < len(dates) && j < (index+1)*telegramKeyboardSize; j++ {
+		if availability[dates[j]] {
+			selection = append(selection, telegramStateYes)
+		} else {
+			selection = append(selection, telegramStateNo)
+		}
+	}
+
+	return string(selection)
+}
+
+// Apply a keyboard entry's selection to the person's availability
+func (m *Manager) telegramApplySelection(entry *telegramEntry) {
+	dates := m.generateTimestamps()
+
+	m.edit.Lock()
+	defer m.edit.Unlock()
+
+	if _, ok := m.availability[entry.Person]; !ok {
+		m.availability[entry.Person] = m.generateAvailability()
+	}
+	if _, ok := m.tentative[entry.Person]; !ok {
+		m.tentative[entry.Person] = m.generateAvailability()
+	}
+
+	for j, state := range []byte(entry.Selection) {
+		// Ignore selections for dates that are no longer being asked about
+		if entry.Index*telegramKeyboardSize+j >= len(dates) {
+			break
+		}
+
+		date := dates[entry.Index*telegramKeyboardSize+j]
+		m.availability[entry.Person][date] = state == telegramStateYes
+		m.tentative[entry.Person][date] = state == telegramStateMaybe
+	}
+}
+
+// Get the icon shown for a keyboard state
+func telegramStateIcon(state byte) string {
+	switch state {
+	case telegramStateYes:
+		return "✅"
+	case telegramStateMaybe:
+		return "❔"
+	default:
+		return "⬜"
+	}
+}
+
+// Find the telegram person with the given user ID
+func (m *Manager) telegramPerson(userID int64) (Person, bool) {
+	id := strconv.FormatInt(userID, 10)
+	for _, p := range m.config.Persons {
+		if strings.HasPrefix(p.RequestMethod, "telegram") && p.ID == id {
+			return p, true
+		}
+	}
+
+	return Person{}, false
+}
+
+// Get the names of all persons who respond in the telegram group chat
+func (m *Manager) telegramGroupNames() []string {
+	names := []string{}
+	for _, p := range m.config.Persons {
+		if p.RequestMethod == "telegram_group" {
+			names = append(names, p.Name)
+		}
+	}
+
+	return names
+}
+
+// Sort telegram entries based on their index
+func sortTelegramEntries(entries []*telegramEntry) {
+	for i := 1; i < len(entries); i++ {
+		cur := entries[i]
+		j := i - 1
+
+		for j >= 0 && entries[j].Index > cur.Index {
+			entries[j+1] = entries[j]
+			j--
+		}
+		entries[j+1] = cur
+	}
+}
+
+// Create a message formatted with HTML
+func telegramHTMLMessage(chatID int64, text string) telegram.MessageConfig {
+	msg := telegram.NewMessage(chatID, text)
+	msg.ParseMode = telegram.ModeHTML
+
+	return msg
+}
+
+// Get a message in the manager's locale for an HTML formatted message, escaping the values put in it
+func telegramText(manager *Manager, key string, values ...interface{}) string {
+	escaped := make([]interface{}, len(values))
+	for i, value := range values {
+		if s, ok := value.(string); ok {
+			escaped[i] = html.EscapeString(s)
+		} else {
+			escaped[i] = value
+		}
+	}
+
+	return manager.text(key, escaped...)
+}
+
+// Render a message for an HTML formatted message. Templates are written as plain text, so their output is escaped as
+// a whole, while the default message is only escaped where values are put in it
+func telegramRender(manager *Manager, name string, data MessageData, key string, values ...interface{}) string {
+	if text, ok := manager.execute(name, data); ok {
+		return html.EscapeString(text)
+	}
+
+	return telegramText(manager, key, values...)
+}
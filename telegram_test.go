@@ -1,14 +1,19 @@
 package align_test
 
 import (
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"os/signal"
+	"path"
+	"strings"
 	"syscall"
 	"testing"
 
 	"github.com/ethanbaker/align"
 	telegram "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/require"
 )
 
@@ -16,8 +21,7 @@ func TestTelegram(t *testing.T) {
 	require := require.New(t)
 
 	// Read in telegram credentials
-	env, err := godotenv.Read("./config/telegram/.env.telegram")
-	require.Nil(err)
+	env := readTestEnv(t, "./config/telegram/.env.telegram")
 
 	// Start a telegram session
 	session, err := telegram.NewBotAPI(env["TELEGRAM_TOKEN"])
@@ -53,8 +57,7 @@ func TestTelegramPreSQL(t *testing.T) {
 	require := require.New(t)
 
 	// Read in telegram credentials
-	env, err := godotenv.Read("./config/telegram/.env")
-	require.Nil(err)
+	env := readTestEnv(t, "./config/telegram/.env")
 
 	// Start a telegram session
 	session, err := telegram.NewBotAPI(env["TELEGRAM_TOKEN"])
@@ -80,8 +83,7 @@ func TestTelegramPostSQL(t *testing.T) {
 	require := require.New(t)
 
 	// Read in telegram credentials
-	env, err := godotenv.Read("./config/telegram/.env")
-	require.Nil(err)
+	env := readTestEnv(t, "./config/telegram/.env")
 
 	// Start a telegram session
 	session, err := telegram.NewBotAPI(env["TELEGRAM_TOKEN"])
@@ -100,3 +102,361 @@ func TestTelegramPostSQL(t *testing.T) {
 	// Send response with on completion
 	manager.OnCompletion()
 }
+
+const telegramGroupTestConfig = `
+settings:
+  title: "Group Meetup"
+  interval: 10
+  offset: 2
+  timezone: "America/New_York"
+  contact_time: "0 10 * * 0"
+  deadline_time: "0 10 * * 1"
+
+telegram:
+  chat_id: -100
+
+persons:
+  - name: "Person 1"
+    request_method: "telegram_group"
+    response_method: "telegram_group"
+    id: "1"
+  - name: "Person 2"
+    request_method: "telegram_group"
+    response_method: "telegram_group"
+    id: "2"
+`
+
+func TestTelegramGroupPollAnswer(t *testing.T) {
+	require := require.New(t)
+
+	align.ResetTelegramEntries()
+	t.Cleanup(align.ResetTelegramEntries)
+
+	manager := createTestManager(t, telegramGroupTestConfig)
+	setContactDay(manager)
+	manager.ResetAvailability("Person 1")
+	manager.ResetAvailability("Person 2")
+
+	// Ten dates are asked about in two polls of up to seven dates
+	align.AddTelegramEntry("", align.TelegramModeGroup, 0, "poll-0", -100, 1)
+	align.AddTelegramEntry("", align.TelegramModeGroup, 1, "poll-1", -100, 2)
+	dates := manager.Timestamps()
+
+	// Answers are attributed to the person who voted, with options counted from the start of their poll
+	align.HandleTelegramPollAnswer(manager, &telegram.PollAnswer{PollID: "poll-0", User: telegram.User{ID: 1}, OptionIDs: []int{0, 2}})
+	align.HandleTelegramPollAnswer(manager, &telegram.PollAnswer{PollID: "poll-1", User: telegram.User{ID: 1}, OptionIDs: []int{1}})
+
+	availability := manager.Availability("Person 1")
+	for j, date := range dates {
+		require.Equal(j == 0 || j == 2 || j == 8, availability[date], date)
+	}
+	require.NotContains(manager.Availability("Person 2"), true)
+
+	// Retracted votes clear the dates of their poll
+	align.HandleTelegramPollAnswer(manager, &telegram.PollAnswer{PollID: "poll-0", User: telegram.User{ID: 1}})
+
+	availability = manager.Availability("Person 1")
+	require.False(availability[dates[0]])
+	require.False(availability[dates[2]])
+	require.True(availability[dates[8]])
+
+	// Answers from unknown users and to unknown polls are ignored
+	align.HandleTelegramPollAnswer(manager, &telegram.PollAnswer{PollID: "poll-0", User: telegram.User{ID: 3}, OptionIDs: []int{0}})
+	align.HandleTelegramPollAnswer(manager, &telegram.PollAnswer{PollID: "poll-9", User: telegram.User{ID: 2}, OptionIDs: []int{0}})
+	require.NotContains(manager.Availability("Person 2"), true)
+}
+
+func TestTelegramFormatResponse(t *testing.T) {
+	require := require.New(t)
+
+	manager := createTestManager(t, telegramGroupTestConfig)
+
+	// Every available day is listed with the persons free on it
+	response := align.TelegramFormatResponse(manager, []string{"Person 2"}, 1, align.NewDay("2024-01-09", "Person 1"))
+	require.Contains(response, "<b>Schedule results for Group Meetup</b>")
+	require.Contains(response, "1/2 people available")
+	require.Contains(response, "- Tuesday 01/09 (Person 1)\n")
+	require.Contains(response, "No responses from:\n- Person 2\n")
+}
+
+func TestTelegramPollAnswerVoter(t *testing.T) {
+	require := require.New(t)
+
+	align.ResetTelegramEntries()
+	t.Cleanup(align.ResetTelegramEntries)
+
+	manager := createTestManager(t, strings.ReplaceAll(telegramGroupTestConfig, `"telegram_group"`, `"telegram"`))
+	setContactDay(manager)
+	manager.ResetAvailability("Person 1")
+	manager.ResetAvailability("Person 2")
+
+	align.AddTelegramEntry("Person 1", align.TelegramModeDirect, 0, "poll-1", 1, 1)
+	dates := manager.Timestamps()
+
+	// Answers to private polls are read from poll answers rather than the poll's tally
+	align.HandleTelegramPollAnswer(manager, &telegram.PollAnswer{PollID: "poll-1", User: telegram.User{ID: 1}, OptionIDs: []int{1}})
+	require.True(manager.Availability("Person 1")[dates[1]])
+
+	// A forwarded poll only changes the availability of the person who voted
+	align.HandleTelegramPollAnswer(manager, &telegram.PollAnswer{PollID: "poll-1", User: telegram.User{ID: 2}, OptionIDs: []int{3}})
+	require.True(manager.Availability("Person 2")[dates[3]])
+	require.False(manager.Availability("Person 1")[dates[3]])
+	require.True(manager.Availability("Person 1")[dates[1]])
+}
+
+func TestTelegramAnonymousPoll(t *testing.T) {
+	require := require.New(t)
+
+	align.ResetTelegramEntries()
+	t.Cleanup(align.ResetTelegramEntries)
+
+	manager := createTestManager(t, strings.ReplaceAll(telegramGroupTestConfig, `"telegram_group"`, `"telegram"`))
+	setContactDay(manager)
+	manager.ResetAvailability("Person 1")
+	manager.ResetAvailability("Person 2")
+
+	align.AddTelegramEntry("Person 1", align.TelegramModeDirect, 1, "poll-1", 1, 1)
+	align.AddTelegramEntry("", align.TelegramModeGroup, 0, "poll-2", -100, 2)
+	dates := manager.Timestamps()
+
+	// Polls restored from before votes were attributed are read from their tally, counted from the start of the poll
+	poll := &telegram.Poll{ID: "poll-1", IsAnonymous: true, Options: []telegram.PollOption{{VoterCount: 0}, {VoterCount: 1}, {VoterCount: 0}}}
+	align.HandleTelegramPoll(manager, poll)
+
+	availability := manager.Availability("Person 1")
+	for j, date := range dates {
+		require.Equal(j == 8, availability[date], date)
+	}
+
+	// Retracted votes clear the dates of the poll
+	poll.Options[1].VoterCount = 0
+	align.HandleTelegramPoll(manager, poll)
+	require.NotContains(manager.Availability("Person 1"), true)
+
+	// Tallies of group polls can't be attributed to a person
+	align.HandleTelegramPoll(manager, &telegram.Poll{ID: "poll-2", IsAnonymous: true, Options: []telegram.PollOption{{VoterCount: 1}}})
+	require.NotContains(manager.Availability("Person 1"), true)
+	require.NotContains(manager.Availability("Person 2"), true)
+}
+
+// A request the test bot received
+type telegramTestRequest struct {
+	Method string
+	Params url.Values
+}
+
+// Create a telegram bot that records its requests to a local server instead of sending them
+func newTelegramTestBot(t *testing.T) (*telegram.BotAPI, *[]telegramTestRequest) {
+	requests := &[]telegramTestRequest{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Nil(t, r.ParseForm())
+		*requests = append(*requests, telegramTestRequest{Method: path.Base(r.URL.Path), Params: r.PostForm})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	bot := &telegram.BotAPI{Token: "token", Client: server.Client()}
+	bot.SetAPIEndpoint(server.URL + "/bot%s/%s")
+
+	return bot, requests
+}
+
+const telegramKeyboardTestConfig = `
+settings:
+  title: "Group Meetup"
+  interval: 7
+  offset: 2
+  timezone: "America/New_York"
+  contact_time: "0 10 * * 0"
+  deadline_time: "0 10 * * 1"
+
+persons:
+  - name: "Person 1"
+    request_method: "telegram_keyboard"
+    response_method: "telegram"
+    id: "1"
+  - name: "Person 2"
+    request_method: "telegram_keyboard"
+    response_method: "telegram"
+    id: "2"
+`
+
+func TestTelegramKeyboardCallback(t *testing.T) {
+	require := require.New(t)
+
+	align.ResetTelegramEntries()
+	t.Cleanup(align.ResetTelegramEntries)
+
+	manager := createTestManager(t, telegramKeyboardTestConfig)
+	setContactDay(manager)
+	dates := manager.Timestamps()
+
+	bot, requests := newTelegramTestBot(t)
+	align.AddTelegramKeyboardEntry("Person 1", 0, 1, 10, "nnnnnnn")
+
+	press := func(userID int64, data string) {
+		align.HandleTelegramCallback(manager, bot, &telegram.CallbackQuery{
+			ID:      "query",
+			From:    &telegram.User{ID: userID},
+			Message: &telegram.Message{MessageID: 10, Chat: &telegram.Chat{ID: 1}},
+			Data:    data,
+		})
+	}
+
+	// Each press cycles a date from no to yes to maybe and redraws the keyboard
+	press(1, "align:2")
+	require.Equal("nnynnnn", align.TelegramSelection(1, 10))
+	require.True(manager.Availability("Person 1")[dates[2]])
+	require.Equal("editMessageReplyMarkup", (*requests)[0].Method)
+	require.Equal("answerCallbackQuery", (*requests)[1].Method)
+	require.Equal("✅ Thursday 01/11", (*requests)[1].Params.Get("text"))
+
+	press(1, "align:2")
+	require.Equal("nnmnnnn", align.TelegramSelection(1, 10))
+	require.False(manager.Availability("Person 1")[dates[2]])
+	require.True(manager.Tentative("Person 1")[dates[2]])
+
+	press(1, "align:2")
+	require.Equal("nnnnnnn", align.TelegramSelection(1, 10))
+	require.False(manager.Tentative("Person 1")[dates[2]])
+
+	// Presses outside of the keyboard are ignored
+	*requests = nil
+	press(1, "align:7")
+	press(1, "other:1")
+	require.Equal("nnnnnnn", align.TelegramSelection(1, 10))
+	require.Empty(*requests)
+
+	// Other persons can't change the keyboard
+	press(2, "align:0")
+	require.Equal("nnnnnnn", align.TelegramSelection(1, 10))
+	require.Equal("This schedule belongs to someone else", (*requests)[0].Params.Get("text"))
+
+	// Finishing the selection replaces the keyboard with a summary
+	*requests = nil
+	press(1, "align:0")
+	press(1, "align:done")
+	require.Equal("editMessageText", (*requests)[2].Method)
+	require.Contains((*requests)[2].Params.Get("text"), "✅ Tuesday 01/09\n⬜ Wednesday 01/10\n")
+	require.Equal("Saved", (*requests)[3].Params.Get("text"))
+
+	// Keyboards without an entry are closed
+	*requests = nil
+	align.ResetTelegramEntries()
+	press(1, "align:0")
+	require.Equal("This schedule is closed", (*requests)[0].Params.Get("text"))
+}
+
+func TestTelegramKeyboardMarkup(t *testing.T) {
+	require := require.New(t)
+
+	manager := createTestManager(t, telegramKeyboardTestConfig)
+	dates := []string{"2024-01-09", "2024-01-10", "2024-01-11"}
+
+	// Every date gets a button with its state, followed by a done button
+	markup := align.TelegramKeyboardMarkup(manager, 0, "nym", dates)
+	require.Len(markup.InlineKeyboard, 4)
+	require.Equal("⬜ Tuesday 01/09", markup.InlineKeyboard[0][0].Text)
+	require.Equal("✅ Wednesday 01/10", markup.InlineKeyboard[1][0].Text)
+	require.Equal("❔ Thursday 01/11", markup.InlineKeyboard[2][0].Text)
+	require.Equal("align:1", *markup.InlineKeyboard[1][0].CallbackData)
+	require.Equal("Done", markup.InlineKeyboard[3][0].Text)
+	require.Equal("align:done", *markup.InlineKeyboard[3][0].CallbackData)
+}
+
+func TestTelegramCommand(t *testing.T) {
+	require := require.New(t)
+
+	align.ResetTelegramEntries()
+	t.Cleanup(align.ResetTelegramEntries)
+
+	manager := createTestManager(t, telegramKeyboardTestConfig)
+	bot, requests := newTelegramTestBot(t)
+
+	command := func(userID int64, text string) string {
+		*requests = nil
+		align.HandleTelegramCommand(manager, bot, &telegram.Message{
+			MessageID: 5,
+			From:      &telegram.User{ID: userID},
+			Chat:      &telegram.Chat{ID: userID},
+			Text:      text,
+			Entities:  []telegram.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(text)}},
+		})
+
+		require.Len(*requests, 1)
+		require.Equal("sendMessage", (*requests)[0].Method)
+		require.Equal("5", (*requests)[0].Params.Get("reply_to_message_id"))
+		return html.UnescapeString((*requests)[0].Params.Get("text"))
+	}
+
+	// Commands need a known person, and most need a running schedule
+	require.Equal("You are not part of this schedule", command(3, "/status"))
+	require.Contains(command(1, "/help"), "/myavailability")
+	require.Equal("Unknown command, use /help to see all commands", command(1, "/other"))
+	require.Equal("There are no results yet", command(1, "/results"))
+	require.Equal("There is no schedule running right now", command(1, "/status"))
+
+	setContactDay(manager)
+
+	// The status describes the person's answer and lists who is still pending
+	status := command(1, "/status")
+	require.Contains(status, "You haven't answered yet")
+	require.Contains(status, "Still waiting on: Person 1, Person 2")
+
+	require.Equal("You opted out of the schedule for Group Meetup", command(2, "/skip"))
+	require.Contains(command(2, "/status"), "You opted out of this schedule")
+
+	// Answering removes the person from the pending list and shows their answer
+	align.AddTelegramKeyboardEntry("Person 1", 0, 1, 10, "nmynnnn")
+	align.HandleTelegramCallback(manager, bot, &telegram.CallbackQuery{
+		ID:      "query",
+		From:    &telegram.User{ID: 1},
+		Message: &telegram.Message{MessageID: 10, Chat: &telegram.Chat{ID: 1}},
+		Data:    "align:done",
+	})
+
+	status = command(1, "/status")
+	require.Contains(status, "You have answered")
+	require.Contains(status, "Everyone has answered")
+	require.Contains(command(1, "/myavailability"), "⬜ Tuesday 01/09\n❔ Wednesday 01/10\n✅ Thursday 01/11\n")
+}
+
+func TestTelegramPick(t *testing.T) {
+	require := require.New(t)
+
+	config := strings.Replace(telegramKeyboardTestConfig, "    id: \"1\"\n", "    id: \"1\"\n    organizer: true\n", 1)
+	manager := createTestManager(t, config)
+	setContactDay(manager)
+	manager.Awaiting = true
+
+	bot, requests := newTelegramTestBot(t)
+
+	pick := func(userID int64, date string) string {
+		*requests = nil
+		align.HandleTelegramCallback(manager, bot, &telegram.CallbackQuery{
+			ID:      "query",
+			From:    &telegram.User{ID: userID},
+			Message: &telegram.Message{MessageID: 20, Chat: &telegram.Chat{ID: userID}},
+			Data:    "align:pick:" + date,
+		})
+
+		return (*requests)[len(*requests)-1].Params.Get("text")
+	}
+
+	// Only organizers can pick, and only dates of the current schedule
+	require.Equal("Only organizers can pick the final day", pick(2, "2024-01-10"))
+	require.Equal("This schedule is closed", pick(1, "2024-01-03"))
+	require.Nil(manager.Decision)
+
+	// Picks carry the date they are for and replace the keyboard with the decision
+	require.Equal("Decided on Wednesday 01/10", pick(1, "2024-01-10"))
+	require.Equal("editMessageText", (*requests)[1].Method)
+	require.Equal("2024-01-10", manager.Decision.Date.Format("2006-01-02"))
+
+	// Once a day is picked, other picks are refused
+	require.Equal("The final day has already been picked", pick(1, "2024-01-11"))
+	require.Equal("2024-01-10", manager.Decision.Date.Format("2006-01-02"))
+}
@@ -0,0 +1,195 @@
+package align
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+)
+
+/* ---- TYPES ---- */
+
+// MessageDate represents a date listed in a message
+type MessageDate struct {
+	Date      string   // The formatted date
+	Emoji     string   // The emoji persons react with for the date, if the message has reactions
+	Busy      bool     // Whether the person's calendar is busy on the date
+	Available []string // Persons available on the date
+	Tentative []string // Persons who might be available on the date
+}
+
+// MessageData represents the values a message template has access to
+type MessageData struct {
+	Title     string        // The title of the group
+	Mention   string        // Who the message is for, if it is sent to a shared channel
+	Deadline  string        // When persons have to answer by
+	Date      string        // When the decided event takes place
+	Dates     []MessageDate // The dates persons are asked about, or the best days in results
+	Attendees []string      // Persons available on the best day, or going to the decided event
+	Unknowns  []string      // Persons who didn't answer
+	Excluded  []string      // Dates persons weren't asked about
+	Available int           // How many persons are available on the best days
+	Total     int           // How many persons are in the group
+}
+
+/* ---- GLOBALS ---- */
+
+// Names of the messages a group can replace with its own templates
+const (
+	templateHeader      = "header"
+	templateRequestBody = "request_body"
+	templateReminder    = "reminder"
+	templateResult      = "result"
+	templateNoResult    = "no_result"
+)
+
+// Functions templates can use besides the text/template builtins
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+/* ---- FUNCTIONS ---- */
+
+// Parse the group's message templates. Templates are run once against sample data, so mistakes such as unknown fields
+// are found when the config is loaded instead of when the message is sent
+func (m *Manager) loadTemplates() error {
+	sources := map[string]string{
+		templateHeader:      m.config.Templates.Header,
+		templateRequestBody: m.config.Templates.RequestBody,
+		templateReminder:    m.config.Templates.Reminder,
+		templateResult:      m.config.Templates.Result,
+		templateNoResult:    m.config.Templates.NoResult,
+	}
+
+	now := m.clock.Now().In(m.loc)
+	sample := MessageData{
+		Title:     m.config.Title,
+		Mention:   "Person 1",
+		Deadline:  m.formatDateTime(now),
+		Date:      m.FormatDate(now),
+		Dates:     []MessageDate{{Date: m.FormatDate(now), Emoji: emojis[0], Available: []string{"Person 1"}}},
+		Attendees: []string{"Person 1"},
+		Unknowns:  []string{"Person 2"},
+		Excluded:  []string{m.FormatDate(now)},
+		Available: 1,
+		Total:     2,
+	}
+
+	m.templates = map[string]*template.Template{}
+	for name, source := range sources {
+		if source == "" {
+			continue
+		}
+
+		t, err := template.New(name).Funcs(templateFuncs).Parse(source)
+		if err != nil {
+			return fmt.Errorf("invalid '%v' template (err: %v)", name, err)
+		}
+
+		if err := t.Execute(&bytes.Buffer{}, sample); err != nil {
+			return fmt.Errorf("invalid '%v' template (err: %v)", name, err)
+		}
+
+		m.templates[name] = t
+	}
+
+	return nil
+}
+
+// Render a message with the group's template of the given name, or with the message of the given key in the locale
+// catalog if the group didn't replace it
+func (m *Manager) render(name string, data MessageData, key string, values ...interface{}) string {
+	if text, ok := m.execute(name, data); ok {
+		return text
+	}
+
+	return m.text(key, values...)
+}
+
+// Run the group's template of the given name. Returns false if the group didn't replace the message or the template
+// can't be run, in which case the default message is used
+func (m *Manager) execute(name string, data MessageData) (string, bool) {
+	t, ok := m.templates[name]
+	if !ok {
+		return "", false
+	}
+
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		log.Printf("[ERR]: cannot render '%v' template, using default message (err: %v)\n", name, err)
+		return "", false
+	}
+
+	return b.String(), true
+}
+
+// Get the values of an availability request sent to a person in the given timezone
+func (m *Manager) requestData(loc *time.Location, mention string) MessageData {
+	dates := []MessageDate{}
+	for _, date := range m.generateTimestamps() {
+		dates = append(dates, MessageDate{Date: m.formatTimestamp(date)})
+	}
+
+	return MessageData{
+		Title:    m.config.Title,
+		Mention:  mention,
+		Deadline: m.deadline(loc),
+		Dates:    dates,
+		Total:    len(m.config.Persons),
+	}
+}
+
+// Get the values of the results of a schedule
+func (m *Manager) resultData(days []day, unknowns []string, available int) MessageData {
+	data := MessageData{
+		Title:     m.config.Title,
+		Dates:     []MessageDate{},
+		Attendees: []string{},
+		Unknowns:  unknowns,
+		Excluded:  m.excludedDates(),
+		Available: available,
+		Total:     len(m.config.Persons),
+	}
+
+	for _, d := range days {
+		data.Dates = append(data.Dates, MessageDate{Date: m.formatTimestamp(d.Timestamp), Available: d.AvailablePersons, Tentative: d.TentativePersons})
+	}
+
+	if len(days) > 0 {
+		data.Attendees = days[0].AvailablePersons
+	}
+
+	return data
+}
+
+// Get the values of a message about the decided event, which takes place at the given time on the person's clock
+func (m *Manager) eventData(event Event, when string, mention string) MessageData {
+	data := MessageData{
+		Title:     event.Title,
+		Mention:   mention,
+		Date:      when,
+		Attendees: event.Attendees,
+		Total:     len(m.config.Persons),
+	}
+
+	// If persons confirm, only those going attend
+	if m.config.Confirm {
+		data.Attendees, _, _ = m.confirmations()
+	}
+	data.Available = len(data.Attendees)
+
+	return data
+}
+
+// Render the header of an availability request sent to a person in the given timezone, or to everyone mentioned in a
+// shared channel
+func (m *Manager) requestHeader(key string, loc *time.Location, mention string) string {
+	data := m.requestData(loc, mention)
+	if mention != "" {
+		return m.render(templateHeader, data, key, data.Title, mention, data.Deadline)
+	}
+
+	return m.render(templateHeader, data, key, data.Title, data.Deadline)
+}
@@ -0,0 +1,170 @@
+package align
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+/* ---- FUNCTIONS ---- */
+
+// Load the timezones of persons who live somewhere other than the group, and check when persons can be contacted
+func (m *Manager) loadPersons() error {
+	if m.config.QuietHours != "" {
+		if _, _, err := parseQuietHours(m.config.QuietHours); err != nil {
+			return err
+		}
+	}
+
+	m.locations = map[string]*time.Location{}
+	for _, person := range m.config.Persons {
+		if person.ContactTime != "" {
+			if _, err := cron.ParseStandard(person.ContactTime); err != nil {
+				return fmt.Errorf("invalid contact time '%v' for person '%v' (err: %v)", person.ContactTime, person.Name, err)
+			}
+		}
+
+		if person.QuietHours != "" {
+			if _, _, err := parseQuietHours(person.QuietHours); err != nil {
+				return fmt.Errorf("%v for person '%v'", err, person.Name)
+			}
+		}
+
+		if person.Timezone == "" {
+			continue
+		}
+
+		loc, err := time.LoadLocation(person.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone '%v' for person '%v' (err: %v)", person.Timezone, person.Name, err)
+		}
+
+		m.locations[person.Name] = loc
+	}
+
+	return nil
+}
+
+// Get the timezone a person lives in, which is the group's timezone unless the person has their own
+func (m *Manager) location(person Person) *time.Location {
+	if loc, ok := m.locations[person.Name]; ok {
+		return loc
+	}
+
+	return m.loc
+}
+
+// Get when a person should be contacted for a schedule started at the given time. Persons in their own timezone or
+// with their own contact time are contacted when their contact time comes around on their own clock, or right away if
+// that has already passed
+func (m *Manager) contactTime(person Person, start time.Time) (time.Time, error) {
+	loc := m.location(person)
+	if loc == m.loc && person.ContactTime == "" {
+		return start, nil
+	}
+
+	contactTime := m.config.ContactTime
+	if person.ContactTime != "" {
+		contactTime = person.ContactTime
+	}
+
+	schedule, err := cron.ParseStandard(contactTime)
+	if err != nil {
+		return start, err
+	}
+
+	// Find the contact time on the person's clock for the day the schedule started
+	year, month, day := start.In(m.loc).Date()
+	next := schedule.Next(time.Date(year, month, day, 0, 0, 0, 0, loc).Add(-time.Second))
+	if next.Before(start) {
+		return start, nil
+	}
+
+	return next, nil
+}
+
+// Parse quiet hours, such as '22:00-08:00', into the minutes after midnight they start and end at
+func parseQuietHours(value string) (int, int, error) {
+	first, last, ok := strings.Cut(value, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid quiet hours '%v'", value)
+	}
+
+	start, err := time.Parse(EVENT_TIME_FORMAT, strings.TrimSpace(first))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid quiet hours '%v' (err: %v)", value, err)
+	}
+
+	end, err := time.Parse(EVENT_TIME_FORMAT, strings.TrimSpace(last))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid quiet hours '%v' (err: %v)", value, err)
+	}
+
+	if start.Equal(end) {
+		return 0, 0, fmt.Errorf("quiet hours '%v' start and end at the same time", value)
+	}
+
+	return start.Hour()*60 + start.Minute(), end.Hour()*60 + end.Minute(), nil
+}
+
+// Check whether a point in time is during a person's quiet hours, returning when the quiet hours end
+func (m *Manager) quietUntil(person Person, t time.Time) (time.Time, bool) {
+	value := m.config.QuietHours
+	if person.QuietHours != "" {
+		value = person.QuietHours
+	}
+
+	if value == "" {
+		return t, false
+	}
+
+	start, end, err := parseQuietHours(value)
+	if err != nil {
+		log.Printf("[ERR]: cannot parse quiet hours (err: %v)\n", err)
+		return t, false
+	}
+
+	// Quiet hours are on the person's own clock
+	loc := m.location(person)
+	local := t.In(loc)
+	year, month, day := local.Date()
+	minute := local.Hour()*60 + local.Minute()
+
+	switch {
+	// Quiet hours within a single day, such as '13:00-14:00'
+	case start < end && minute >= start && minute < end:
+		return time.Date(year, month, day, end/60, end%60, 0, 0, loc), true
+
+	// Quiet hours over midnight, such as '22:00-08:00'
+	case start > end && minute >= start:
+		return time.Date(year, month, day+1, end/60, end%60, 0, 0, loc), true
+	case start > end && minute < end:
+		return time.Date(year, month, day, end/60, end%60, 0, 0, loc), true
+	}
+
+	return t, false
+}
+
+// Get the first deadline after a point in time
+func (m *Manager) nextDeadline(t time.Time) (time.Time, error) {
+	schedule, err := parseSpec(m.config.DeadlineTime, m.loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return schedule.Next(t), nil
+}
+
+// Describe the next deadline on the clock of the given timezone
+func (m *Manager) deadline(loc *time.Location) string {
+	next, err := m.nextDeadline(m.clock.Now())
+	if err != nil {
+		log.Printf("[ERR]: cannot parse deadline time (err: %v)\n", err)
+		return ""
+	}
+
+	return m.formatDateTime(next.In(loc))
+}